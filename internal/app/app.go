@@ -1,17 +1,29 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/uygardeniz/habit-tracker/internal/config"
 	"github.com/uygardeniz/habit-tracker/internal/handler"
+	"github.com/uygardeniz/habit-tracker/internal/mailer"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	"github.com/uygardeniz/habit-tracker/internal/oidc"
 	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/scheduler"
 	authUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/auth"
 	completionUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/completion"
 	habitUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/habit"
+	identityUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/identity"
+	sessionUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/session"
+	statsUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/stats"
+	timeOffUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/timeoff"
+	timerUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/timer"
 	userUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/user"
 )
 
@@ -19,9 +31,17 @@ type Application struct {
 	Logger            *log.Logger
 	DB                *sql.DB
 	AuthHandler       *handler.AuthHandler
+	EmailAuthHandler  *handler.EmailAuthHandler
 	HabitHandler      *handler.HabitHandler
 	CompletionHandler *handler.CompletionHandler
 	UserHandler       *handler.UserHandler
+	StatsHandler      *handler.StatsHandler
+	TimerHandler      *handler.TimerHandler
+	TimeOffHandler    *handler.TimeOffHandler
+	SessionHandler    *handler.SessionHandler
+	IdentityHandler   *handler.IdentityHandler
+	AuthMiddleware    *middleware.AuthMiddleware
+	Scheduler         *scheduler.Scheduler
 }
 
 func NewApplication() (*Application, error) {
@@ -37,42 +57,188 @@ func NewApplication() (*Application, error) {
 	userRepository := repository.NewPostgresUserRepository(db)
 	habitRepository := repository.NewPostgresHabitRepository(db)
 	completionRepository := repository.NewPostgresCompletionRepository(db)
+	streakSweepRepository := repository.NewPostgresStreakSweepRepository(db)
+	timerRepository := repository.NewPostgresTimerRepository(db)
+	timeOffRepository := repository.NewPostgresTimeOffRepository(db)
+	refreshTokenRepository := repository.NewPostgresRefreshTokenRepository(db)
+	sessionRepository := repository.NewPostgresSessionRepository(db)
+	identityRepository := repository.NewPostgresIdentityRepository(db)
+	passwordRepository := repository.NewPostgresPasswordRepository(db)
+	emailTokenRepository := repository.NewPostgresEmailTokenRepository(db)
+	oauthStateRepository := repository.NewPostgresOAuthStateRepository(db)
+
+	// Initialize identity providers
+	providerConfigs, err := config.GetIdentityProviderConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]oidc.Provider, 0, len(providerConfigs))
+	for _, providerConfig := range providerConfigs {
+		providers = append(providers, oidc.NewOIDCProvider(oidc.Config{
+			Name:         providerConfig.Name,
+			Issuer:       providerConfig.Issuer,
+			ClientID:     providerConfig.ClientID,
+			ClientSecret: providerConfig.ClientSecret,
+			RedirectURL:  providerConfig.RedirectURL,
+			Scopes:       providerConfig.Scopes,
+			TrustEmail:   providerConfig.TrustEmail,
+		}, nil))
+	}
+	providerRegistry := oidc.NewRegistry(providers...)
+
+	// Initialize mailer
+	mailerConfig, err := config.GetMailerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var emailMailer mailer.Mailer = mailer.NewLogMailer(logger)
+	if mailerConfig.Driver == config.MailerSMTP {
+		emailMailer, err = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     mailerConfig.SMTPHost,
+			Port:     mailerConfig.SMTPPort,
+			Username: mailerConfig.SMTPUsername,
+			Password: mailerConfig.SMTPPassword,
+			From:     mailerConfig.From,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	frontendURL := config.GetFrontendURL()
 
 	// Initialize user usecases
 	getMeUsecase := userUsecase.NewGetMeUsecase(userRepository)
 	getUserByIDUsecase := userUsecase.NewGetUserByIDUsecase(userRepository)
+	updateMeUsecase := userUsecase.NewUpdateMeUsecase(userRepository)
 
 	// Initialize auth usecases
-	loginOrRegisterGoogleUserUsecase := authUsecase.NewLoginOrRegisterGoogleUserUsecase(userRepository)
+	loginOrRegisterUserUsecase := authUsecase.NewLoginOrRegisterUserUsecase(userRepository, identityRepository)
+	issueRefreshTokenUsecase := authUsecase.NewIssueRefreshTokenUsecase(sessionRepository)
+	rotateRefreshTokenUsecase := authUsecase.NewRotateRefreshTokenUsecase(refreshTokenRepository)
+	revokeRefreshTokenUsecase := authUsecase.NewRevokeRefreshTokenUsecase(refreshTokenRepository)
+	registerWithPasswordUsecase := authUsecase.NewRegisterWithPasswordUsecase(userRepository, passwordRepository, emailTokenRepository, emailMailer, frontendURL)
+	sendVerificationEmailUsecase := authUsecase.NewSendVerificationEmailUsecase(userRepository, emailTokenRepository, emailMailer, frontendURL)
+	verifyEmailUsecase := authUsecase.NewVerifyEmailUsecase(userRepository, passwordRepository, emailTokenRepository)
+	loginWithPasswordUsecase := authUsecase.NewLoginWithPasswordUsecase(userRepository, passwordRepository)
+	requestPasswordResetUsecase := authUsecase.NewRequestPasswordResetUsecase(userRepository, emailTokenRepository, emailMailer, frontendURL)
+	resetPasswordUsecase := authUsecase.NewResetPasswordUsecase(passwordRepository, emailTokenRepository)
+	requestMagicLinkUsecase := authUsecase.NewRequestMagicLinkUsecase(userRepository, emailTokenRepository, emailMailer, frontendURL)
+	loginWithMagicLinkUsecase := authUsecase.NewLoginWithMagicLinkUsecase(userRepository, emailTokenRepository)
+	changePasswordUsecase := authUsecase.NewChangePasswordUsecase(passwordRepository)
+	consumeOAuthStateUsecase := authUsecase.NewConsumeOAuthStateUsecase(oauthStateRepository)
 
 	// Initialize habit usecases
-	createHabitUsecase := habitUsecase.NewCreateHabitUsecase(habitRepository)
+	createHabitUsecase := habitUsecase.NewCreateHabitUsecase(habitRepository, userRepository)
 	getHabitUsecase := habitUsecase.NewGetHabitUsecase(habitRepository)
 	getHabitsByUserUsecase := habitUsecase.NewGetHabitsByUserUsecase(habitRepository)
-	updateHabitUsecase := habitUsecase.NewUpdateHabitUsecase(habitRepository)
+	updateHabitUsecase := habitUsecase.NewUpdateHabitUsecase(habitRepository, userRepository)
 	deleteHabitUsecase := habitUsecase.NewDeleteHabitUsecase(habitRepository)
+	getDueHabitsUsecase := habitUsecase.NewGetDueHabitsUsecase(habitRepository, completionRepository, timeOffRepository, userRepository)
+	getStreakFreezesUsecase := habitUsecase.NewGetStreakFreezesUsecase(habitRepository, completionRepository, timeOffRepository, userRepository)
+	sweepBrokenStreaksUsecase := habitUsecase.NewSweepBrokenStreaksUsecase(habitRepository, completionRepository, timeOffRepository, streakSweepRepository, userRepository)
 
 	// Initialize completion usecases
-	createCompletionUsecase := completionUsecase.NewCreateCompletionUsecase(completionRepository, habitRepository)
+	createCompletionUsecase := completionUsecase.NewCreateCompletionUsecase(completionRepository, habitRepository, userRepository)
 	getCompletionUsecase := completionUsecase.NewGetCompletionUsecase(completionRepository)
-	getCompletionsUsecase := completionUsecase.NewGetCompletionsUsecase(completionRepository)
-	updateCompletionUsecase := completionUsecase.NewUpdateCompletionUsecase(completionRepository, habitRepository)
-	deleteCompletionUsecase := completionUsecase.NewDeleteCompletionUsecase(completionRepository, habitRepository)
+	getCompletionsUsecase := completionUsecase.NewGetCompletionsUsecase(completionRepository, timeOffRepository)
+	updateCompletionUsecase := completionUsecase.NewUpdateCompletionUsecase(completionRepository, habitRepository, userRepository)
+	deleteCompletionUsecase := completionUsecase.NewDeleteCompletionUsecase(completionRepository, habitRepository, userRepository)
+	checkInUsecase := completionUsecase.NewCheckInUsecase(completionRepository, habitRepository, userRepository)
+	undoCheckInUsecase := completionUsecase.NewUndoCheckInUsecase(completionRepository, habitRepository, userRepository)
+
+	// Initialize stats usecases
+	getHeatmapUsecase := statsUsecase.NewGetHeatmapUsecase(completionRepository, habitRepository, timeOffRepository, userRepository)
+	getHabitStatsUsecase := statsUsecase.NewGetHabitStatsUsecase(habitRepository, completionRepository, timeOffRepository, userRepository)
+	getOverviewUsecase := statsUsecase.NewGetOverviewUsecase(habitRepository, completionRepository, timeOffRepository, userRepository)
+	getCorrelationsUsecase := statsUsecase.NewGetCorrelationsUsecase(habitRepository, completionRepository, timeOffRepository, userRepository)
+
+	// Initialize timer usecases
+	getTimerUsecase := timerUsecase.NewGetTimerUsecase(timerRepository, habitRepository)
+	startTimerUsecase := timerUsecase.NewStartTimerUsecase(timerRepository, habitRepository)
+	pauseTimerUsecase := timerUsecase.NewPauseTimerUsecase(timerRepository, habitRepository)
+	stopTimerUsecase := timerUsecase.NewStopTimerUsecase(timerRepository, habitRepository, userRepository)
+
+	// Initialize time off usecases
+	createSkipUsecase := timeOffUsecase.NewCreateSkipUsecase(timeOffRepository, habitRepository, userRepository)
+	deleteSkipUsecase := timeOffUsecase.NewDeleteSkipUsecase(timeOffRepository, habitRepository, userRepository)
+	getPausesUsecase := timeOffUsecase.NewGetPausesUsecase(timeOffRepository)
+	createPauseUsecase := timeOffUsecase.NewCreatePauseUsecase(timeOffRepository, userRepository)
+	deletePauseUsecase := timeOffUsecase.NewDeletePauseUsecase(timeOffRepository, userRepository)
+
+	// Initialize session usecases
+	getSessionsUsecase := sessionUsecase.NewGetSessionsUsecase(sessionRepository)
+	revokeSessionUsecase := sessionUsecase.NewRevokeSessionUsecase(sessionRepository)
+	revokeAllSessionsUsecase := sessionUsecase.NewRevokeAllSessionsUsecase(sessionRepository)
+	validateSessionUsecase := sessionUsecase.NewValidateSessionUsecase(sessionRepository)
+
+	// Initialize identity usecases
+	getIdentitiesUsecase := identityUsecase.NewGetIdentitiesUsecase(identityRepository)
+	linkIdentityUsecase := identityUsecase.NewLinkIdentityUsecase(identityRepository)
+	unlinkIdentityUsecase := identityUsecase.NewUnlinkIdentityUsecase(identityRepository)
 
 	// Initialize handlers
-	userHandler := handler.NewUserHandler(logger, getMeUsecase)
-	authHandler := handler.NewAuthHandler(logger, loginOrRegisterGoogleUserUsecase, getUserByIDUsecase)
-	habitHandler := handler.NewHabitHandler(createHabitUsecase, getHabitUsecase, updateHabitUsecase, getHabitsByUserUsecase, deleteHabitUsecase, logger, v)
-	completionHandler := handler.NewCompletionHandler(createCompletionUsecase, getCompletionUsecase, getCompletionsUsecase, updateCompletionUsecase, deleteCompletionUsecase, logger, v)
+	userHandler := handler.NewUserHandler(logger, getMeUsecase, updateMeUsecase, v)
+	authHandler := handler.NewAuthHandler(logger, providerRegistry, loginOrRegisterUserUsecase, linkIdentityUsecase, getUserByIDUsecase, issueRefreshTokenUsecase, rotateRefreshTokenUsecase, revokeRefreshTokenUsecase, consumeOAuthStateUsecase)
+	emailAuthHandler := handler.NewEmailAuthHandler(registerWithPasswordUsecase, sendVerificationEmailUsecase, verifyEmailUsecase, loginWithPasswordUsecase, requestPasswordResetUsecase, resetPasswordUsecase, requestMagicLinkUsecase, loginWithMagicLinkUsecase, changePasswordUsecase, issueRefreshTokenUsecase, logger, v)
+	habitHandler := handler.NewHabitHandler(createHabitUsecase, getHabitUsecase, updateHabitUsecase, getHabitsByUserUsecase, deleteHabitUsecase, getDueHabitsUsecase, getStreakFreezesUsecase, logger, v)
+	completionHandler := handler.NewCompletionHandler(createCompletionUsecase, getCompletionUsecase, getCompletionsUsecase, updateCompletionUsecase, deleteCompletionUsecase, checkInUsecase, undoCheckInUsecase, logger, v)
+	statsHandler := handler.NewStatsHandler(getHeatmapUsecase, getHabitStatsUsecase, getOverviewUsecase, getCorrelationsUsecase, logger, v)
+	timerHandler := handler.NewTimerHandler(getTimerUsecase, startTimerUsecase, pauseTimerUsecase, stopTimerUsecase, logger)
+	timeOffHandler := handler.NewTimeOffHandler(createSkipUsecase, deleteSkipUsecase, getPausesUsecase, createPauseUsecase, deletePauseUsecase, logger, v)
+	sessionHandler := handler.NewSessionHandler(getSessionsUsecase, revokeSessionUsecase, revokeAllSessionsUsecase, logger)
+	identityHandler := handler.NewIdentityHandler(providerRegistry, getIdentitiesUsecase, unlinkIdentityUsecase, logger)
+
+	// Initialize middleware
+	authMiddleware := middleware.NewAuthMiddleware(logger, validateSessionUsecase)
+
+	// Initialize background jobs
+	sweepInterval, err := getDurationEnv("STREAK_SWEEP_INTERVAL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	jobScheduler := scheduler.NewScheduler(logger)
+	jobScheduler.Every("streak_sweep", sweepInterval, func(ctx context.Context) error {
+		resets, err := sweepBrokenStreaksUsecase.Execute(ctx, time.Now())
+		if resets > 0 {
+			logger.Printf("Streak sweep reset %d streaks", resets)
+		}
+		return err
+	})
+	jobScheduler.Start()
 
 	app := &Application{
 		Logger:            logger,
 		DB:                db,
 		AuthHandler:       authHandler,
+		EmailAuthHandler:  emailAuthHandler,
 		HabitHandler:      habitHandler,
 		CompletionHandler: completionHandler,
 		UserHandler:       userHandler,
+		StatsHandler:      statsHandler,
+		TimerHandler:      timerHandler,
+		TimeOffHandler:    timeOffHandler,
+		SessionHandler:    sessionHandler,
+		IdentityHandler:   identityHandler,
+		AuthMiddleware:    authMiddleware,
+		Scheduler:         jobScheduler,
 	}
 
 	return app, nil
 }
+
+func getDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return duration, nil
+}
@@ -11,3 +11,5 @@ var ErrDatabase = errors.New("database operation failed")
 var ErrForbidden = errors.New("user is not authorized to perform this action")
 
 var ErrAlreadyExists = errors.New("resource already exists")
+
+var ErrUnauthorized = errors.New("authentication is required")
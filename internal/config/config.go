@@ -0,0 +1,12 @@
+package config
+
+import "os"
+
+var frontendURL string
+
+func GetFrontendURL() string {
+	if frontendURL == "" {
+		frontendURL = os.Getenv("FRONTEND_URL")
+	}
+	return frontendURL
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+// GoogleIssuer is the OpenID Connect issuer Google logins go through
+const GoogleIssuer = "https://accounts.google.com"
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// IdentityProviderConfig configures an OpenID Connect provider users can log in with
+type IdentityProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// Whether the provider's email_verified claim is trusted, letting a first login link
+	// to the existing account with that email
+	TrustEmail bool
+}
+
+// GetIdentityProviderConfigs reads the configured identity providers from the
+// environment. Google is configured through the GOOGLE_OAUTH_* variables. Any other
+// OpenID Connect issuer is listed by name in OIDC_PROVIDERS, e.g. "okta,keycloak",
+// and configured through OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL
+// and optionally a space separated _SCOPES. Issuers have to be https URLs, except on
+// the local host. Only Google's verified emails are trusted by default; another
+// issuer's are trusted when its _TRUST_EMAIL is "true", which should be reserved for
+// issuers that really verify the emails they vouch for.
+func GetIdentityProviderConfigs() ([]IdentityProviderConfig, error) {
+	var configs []IdentityProviderConfig
+
+	if clientID := os.Getenv("GOOGLE_OAUTH_CLIENT_ID"); clientID != "" {
+		configs = append(configs, IdentityProviderConfig{
+			Name:         "google",
+			Issuer:       GoogleIssuer,
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("GOOGLE_OAUTH_REDIRECT_URL"),
+			TrustEmail:   true,
+		})
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !providerNamePattern.MatchString(name) || name == "google" {
+			return nil, fmt.Errorf("invalid identity provider name %q", name)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		config := IdentityProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if trustEmail := os.Getenv(prefix + "TRUST_EMAIL"); trustEmail != "" {
+			trusted, err := strconv.ParseBool(trustEmail)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %sTRUST_EMAIL: %w", prefix, err)
+			}
+			config.TrustEmail = trusted
+		}
+		if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+			return nil, fmt.Errorf("identity provider %s needs %sISSUER, %sCLIENT_ID and %sREDIRECT_URL", name, prefix, prefix, prefix)
+		}
+		if !utils.IsSecureURL(config.Issuer) {
+			return nil, fmt.Errorf("%sISSUER must be an https URL", prefix)
+		}
+
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	MailerLog  = "log"
+	MailerSMTP = "smtp"
+)
+
+// MailerConfig configures how emails to users are sent
+type MailerConfig struct {
+	// MailerLog or MailerSMTP
+	Driver       string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// GetMailerConfig reads the mailer from the environment. MAILER is "smtp" to send
+// through SMTP_HOST, SMTP_PORT (587 by default), SMTP_USERNAME and SMTP_PASSWORD from
+// MAIL_FROM, or "log", the default, to only log emails.
+func GetMailerConfig() (MailerConfig, error) {
+	config := MailerConfig{
+		Driver:       os.Getenv("MAILER"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		From:         os.Getenv("MAIL_FROM"),
+	}
+
+	if config.Driver == "" {
+		config.Driver = MailerLog
+	}
+	if config.SMTPPort == "" {
+		config.SMTPPort = "587"
+	}
+
+	switch config.Driver {
+	case MailerLog:
+	case MailerSMTP:
+		if config.SMTPHost == "" || config.From == "" {
+			return MailerConfig{}, fmt.Errorf("MAILER=smtp requires SMTP_HOST and MAIL_FROM")
+		}
+	default:
+		return MailerConfig{}, fmt.Errorf("invalid MAILER %q, expected %q or %q", config.Driver, MailerLog, MailerSMTP)
+	}
+
+	return config, nil
+}
@@ -22,3 +22,46 @@ type UserData struct {
 	Name    string
 	Picture string
 }
+
+// RegisterDTO represents the request to sign up with an email address and password
+type RegisterDTO struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=128"`
+	Name     string `json:"name" validate:"required,max=255"`
+}
+
+// LoginDTO represents the request to log in with an email address and password
+type LoginDTO struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,max=128"`
+}
+
+// EmailDTO represents a request for a link to be emailed to the address
+type EmailDTO struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// EmailTokenDTO represents the token of a link the user opened from an email
+type EmailTokenDTO struct {
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+// VerifyEmailDTO represents the request to verify an email address with a verification
+// token. Password is required when the user signed up with one.
+type VerifyEmailDTO struct {
+	Token    string `json:"token" validate:"required,max=128"`
+	Password string `json:"password" validate:"max=128"`
+}
+
+// ResetPasswordDTO represents the request to choose a new password with a reset token
+type ResetPasswordDTO struct {
+	Token    string `json:"token" validate:"required,max=128"`
+	Password string `json:"password" validate:"required,min=8,max=128"`
+}
+
+// ChangePasswordDTO represents the request to set the authenticated user's password.
+// CurrentPassword is required when the user already has one.
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"current_password" validate:"max=128"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=128"`
+}
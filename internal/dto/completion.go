@@ -6,15 +6,17 @@ import (
 
 // CreateCompletionDTO represents the request to create a habit completion
 type CreateCompletionDTO struct {
-	CompletionDate string  `json:"completion_date" validate:"required,datetime=2006-01-02"`
-	Count          int     `json:"count" validate:"required,min=1"`
-	Notes          *string `json:"notes" validate:"omitempty,max=1000"`
+	CompletionDate string   `json:"completion_date" validate:"omitempty,datetime=2006-01-02"`
+	Count          int      `json:"count" validate:"omitempty,min=1"`
+	Value          *float64 `json:"value" validate:"omitempty,gt=0,max=1000000"`
+	Notes          *string  `json:"notes" validate:"omitempty,max=1000"`
 }
 
 // UpdateCompletionDTO represents the request to update a habit completion
 type UpdateCompletionDTO struct {
-	Count *int    `json:"count,omitempty" validate:"omitempty,min=1"`
-	Notes *string `json:"notes,omitempty" validate:"omitempty,max=1000"`
+	Count *int     `json:"count,omitempty" validate:"omitempty,min=1"`
+	Value *float64 `json:"value,omitempty" validate:"omitempty,gt=0,max=1000000"`
+	Notes *string  `json:"notes,omitempty" validate:"omitempty,max=1000"`
 }
 
 // CompletionResponseDTO represents the response containing habit completion details
@@ -25,6 +27,11 @@ type CompletionResponseDTO struct {
 	CompletedAt    time.Time `json:"completed_at"`
 	CompletionDate time.Time `json:"completion_date"`
 	Count          int       `json:"count"`
+	Value          *float64  `json:"value,omitempty"`
+	TargetCount    int       `json:"target_count"`
+	TargetValue    *float64  `json:"target_value,omitempty"`
+	Progress       float64   `json:"progress"`
+	IsFulfilled    bool      `json:"is_fulfilled"`
 	Notes          *string   `json:"notes,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 }
@@ -37,3 +44,11 @@ type GetCompletionsQueryDTO struct {
 	Limit     *int    `json:"limit" validate:"omitempty,min=1,max=1000"`
 	Offset    *int    `json:"offset" validate:"omitempty,min=0"`
 }
+
+// CheckinDTO represents a quick check-in that adds to (or takes away from) a day's completion
+// count. Measurable habits check in a value instead.
+type CheckinDTO struct {
+	Date   string   `json:"date" validate:"omitempty,datetime=2006-01-02"`
+	Amount int      `json:"amount" validate:"omitempty,min=1"`
+	Value  *float64 `json:"value" validate:"omitempty,gt=0,max=1000000"`
+}
@@ -9,45 +9,96 @@ import (
 )
 
 type CreateHabitDTO struct {
-	Name        string  `json:"name" validate:"required,min=1,max=255"`
-	Description *string `json:"description" validate:"omitempty,max=1000"`
-	Motivation  *string `json:"motivation" validate:"omitempty,max=1000"`
-	Color       string  `json:"color" validate:"required,hexcolor"`
-	Category    *string `json:"category" validate:"omitempty,max=100"`
-	Frequency   string  `json:"frequency" validate:"required,oneof=daily weekly monthly"`
-	TargetCount int     `json:"target_count" validate:"required,min=1"`
-	TargetDays  *string `json:"target_days" validate:"omitempty,json"`
+	Name         string   `json:"name" validate:"required,min=1,max=255"`
+	Description  *string  `json:"description" validate:"omitempty,max=1000"`
+	Motivation   *string  `json:"motivation" validate:"omitempty,max=1000"`
+	Color        string   `json:"color" validate:"required,hexcolor"`
+	Category     *string  `json:"category" validate:"omitempty,max=100"`
+	Kind         string   `json:"kind" validate:"omitempty,oneof=count measurable quit duration"`
+	Unit         *string  `json:"unit" validate:"omitempty,min=1,max=50"`
+	Frequency    string   `json:"frequency" validate:"required,oneof=daily weekly monthly times_per_week times_per_month interval custom"`
+	TargetCount  int      `json:"target_count" validate:"omitempty,min=1"`
+	TargetValue  *float64 `json:"target_value" validate:"omitempty,gt=0,max=1000000"`
+	TargetDays   *string  `json:"target_days" validate:"omitempty,json"`
+	PeriodTarget *int     `json:"period_target" validate:"omitempty,min=1,max=28"`
+	IntervalDays *int     `json:"interval_days" validate:"omitempty,min=1,max=365"`
+	AnchorDate   *string  `json:"anchor_date" validate:"omitempty,datetime=2006-01-02"`
+	Recurrence   *string  `json:"recurrence" validate:"omitempty,max=500"`
 }
 
 type UpdateHabitDTO struct {
-	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
-	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Motivation  *string `json:"motivation,omitempty" validate:"omitempty,max=1000"`
-	Color       *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
-	Category    *string `json:"category,omitempty" validate:"omitempty,max=100"`
-	Frequency   *string `json:"frequency,omitempty" validate:"omitempty,oneof=daily weekly monthly"`
-	TargetCount *int    `json:"target_count,omitempty" validate:"omitempty,min=1"`
-	TargetDays  *string `json:"target_days,omitempty" validate:"omitempty,json"`
-	IsActive    *bool   `json:"is_active,omitempty"`
+	Name         *string  `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description  *string  `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Motivation   *string  `json:"motivation,omitempty" validate:"omitempty,max=1000"`
+	Color        *string  `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Category     *string  `json:"category,omitempty" validate:"omitempty,max=100"`
+	Kind         *string  `json:"kind,omitempty" validate:"omitempty,oneof=count measurable quit duration"`
+	Unit         *string  `json:"unit,omitempty" validate:"omitempty,min=1,max=50"`
+	Frequency    *string  `json:"frequency,omitempty" validate:"omitempty,oneof=daily weekly monthly times_per_week times_per_month interval custom"`
+	TargetCount  *int     `json:"target_count,omitempty" validate:"omitempty,min=1"`
+	TargetValue  *float64 `json:"target_value,omitempty" validate:"omitempty,gt=0,max=1000000"`
+	TargetDays   *string  `json:"target_days,omitempty" validate:"omitempty,json"`
+	PeriodTarget *int     `json:"period_target,omitempty" validate:"omitempty,min=1,max=28"`
+	IntervalDays *int     `json:"interval_days,omitempty" validate:"omitempty,min=1,max=365"`
+	AnchorDate   *string  `json:"anchor_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Recurrence   *string  `json:"recurrence,omitempty" validate:"omitempty,max=500"`
+	IsActive     *bool    `json:"is_active,omitempty"`
 }
 
 type HabitResponseDTO struct {
-	ID               string    `json:"id"`
-	UserID           string    `json:"user_id"`
-	Name             string    `json:"name"`
-	Description      *string   `json:"description,omitempty"`
-	Motivation       *string   `json:"motivation,omitempty"`
-	Color            string    `json:"color"`
-	Category         *string   `json:"category,omitempty"`
-	Frequency        string    `json:"frequency"`
-	TargetCount      int       `json:"target_count"`
-	TargetDays       *string   `json:"target_days,omitempty"`
-	CurrentStreak    int       `json:"current_streak"`
-	BestStreak       int       `json:"best_streak"`
-	TotalCompletions int       `json:"total_completions"`
-	IsActive         bool      `json:"is_active"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	Name             string     `json:"name"`
+	Description      *string    `json:"description,omitempty"`
+	Motivation       *string    `json:"motivation,omitempty"`
+	Color            string     `json:"color"`
+	Category         *string    `json:"category,omitempty"`
+	Kind             string     `json:"kind"`
+	Unit             *string    `json:"unit,omitempty"`
+	Frequency        string     `json:"frequency"`
+	TargetCount      int        `json:"target_count"`
+	TargetValue      *float64   `json:"target_value,omitempty"`
+	TargetDays       *string    `json:"target_days,omitempty"`
+	PeriodTarget     *int       `json:"period_target,omitempty"`
+	IntervalDays     *int       `json:"interval_days,omitempty"`
+	AnchorDate       *time.Time `json:"anchor_date,omitempty"`
+	Recurrence       *string    `json:"recurrence,omitempty"`
+	CurrentStreak    int        `json:"current_streak"`
+	BestStreak       int        `json:"best_streak"`
+	TotalCompletions int        `json:"total_completions"`
+	IsActive         bool       `json:"is_active"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// DueHabitResponseDTO represents a habit that is due on a day together with that day's progress
+type DueHabitResponseDTO struct {
+	Habit           HabitResponseDTO `json:"habit"`
+	Date            string           `json:"date"`
+	CompletionID    *string          `json:"completion_id,omitempty"`
+	Count           int              `json:"count"`
+	Value           *float64         `json:"value,omitempty"`
+	TargetCount     int              `json:"target_count"`
+	TargetValue     *float64         `json:"target_value,omitempty"`
+	Progress        float64          `json:"progress"`
+	IsFulfilled     bool             `json:"is_fulfilled"`
+	PeriodCompleted *int             `json:"period_completed,omitempty"`
+}
+
+// FreezeEventResponseDTO represents a streak freeze token being earned or used
+type FreezeEventResponseDTO struct {
+	Date    string `json:"date"`
+	Type    string `json:"type"`
+	Balance int    `json:"balance"`
+	Streak  int    `json:"streak"`
+}
+
+// StreakFreezesResponseDTO represents a habit's streak freeze token balance and history
+type StreakFreezesResponseDTO struct {
+	HabitID  string                   `json:"habit_id"`
+	Balance  int                      `json:"balance"`
+	EarnDays int                      `json:"earn_days"`
+	History  []FreezeEventResponseDTO `json:"history"`
 }
 
 // ConvertTargetDaysFromJSON converts JSON string to TargetDays entity
@@ -81,3 +132,17 @@ func ConvertTargetDaysToJSON(targetDays *entity.TargetDays) (*string, error) {
 	targetDaysStr := string(targetDaysJSON)
 	return &targetDaysStr, nil
 }
+
+// ParseDate converts an optional YYYY-MM-DD string to a date
+func ParseDate(date *string) (*time.Time, error) {
+	if date == nil || *date == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		return nil, errors.New("invalid date format")
+	}
+
+	return &parsed, nil
+}
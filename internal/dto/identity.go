@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// IdentityResponseDTO represents an identity provider linked to the user's account
+type IdentityResponseDTO struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     *string   `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// SessionResponseDTO represents one of the user's logged in devices
+type SessionResponseDTO struct {
+	ID         string    `json:"id"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Whether the session is the one making the request
+	IsCurrent bool `json:"is_current"`
+}
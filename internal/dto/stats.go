@@ -0,0 +1,138 @@
+package dto
+
+// HeatmapQueryDTO represents query parameters for the calendar heatmap
+type HeatmapQueryDTO struct {
+	From    *string `json:"from" validate:"omitempty,datetime=2006-01-02"`
+	To      *string `json:"to" validate:"omitempty,datetime=2006-01-02"`
+	HabitID *string `json:"habit_id"`
+}
+
+// HeatmapDayDTO represents the aggregated activity of a single heatmap day
+type HeatmapDayDTO struct {
+	Count     int     `json:"count"`
+	Value     float64 `json:"value,omitempty"`
+	Fulfilled int     `json:"fulfilled"`
+	Scheduled int     `json:"scheduled"`
+	Slips     int     `json:"slips,omitempty"`
+}
+
+// HeatmapResponseDTO represents the calendar heatmap keyed by YYYY-MM-DD. Days with no
+// activity and nothing scheduled are left out.
+type HeatmapResponseDTO struct {
+	From string                   `json:"from"`
+	To   string                   `json:"to"`
+	Days map[string]HeatmapDayDTO `json:"days"`
+}
+
+// HabitStatsQueryDTO represents query parameters for a habit's statistics
+type HabitStatsQueryDTO struct {
+	Series *string `json:"series" validate:"omitempty,oneof=weekly monthly"`
+}
+
+// CompletionRateDTO represents the completion rate over a trailing window of days
+type CompletionRateDTO struct {
+	Days      int     `json:"days"`
+	Scheduled int     `json:"scheduled"`
+	Fulfilled int     `json:"fulfilled"`
+	Rate      float64 `json:"rate"`
+}
+
+// StatsBucketDTO represents one week or month of a time series
+type StatsBucketDTO struct {
+	Start     string  `json:"start"`
+	End       string  `json:"end"`
+	Count     int     `json:"count"`
+	Value     float64 `json:"value,omitempty"`
+	Scheduled int     `json:"scheduled"`
+	Fulfilled int     `json:"fulfilled"`
+	Rate      float64 `json:"rate"`
+}
+
+// HabitStatsResponseDTO represents the response containing a habit's statistics
+type HabitStatsResponseDTO struct {
+	HabitID          string              `json:"habit_id"`
+	CurrentStreak    int                 `json:"current_streak"`
+	BestStreak       int                 `json:"best_streak"`
+	TotalCompletions int                 `json:"total_completions"`
+	CompletionRates  []CompletionRateDTO `json:"completion_rates"`
+	AverageCount     float64             `json:"average_count"`
+	TotalValue       *float64            `json:"total_value,omitempty"`
+	AverageValue     *float64            `json:"average_value,omitempty"`
+	BestWeekday      *string             `json:"best_weekday"`
+	LongestGap       int                 `json:"longest_gap"`
+	Series           string              `json:"series"`
+	TimeSeries       []StatsBucketDTO    `json:"time_series"`
+}
+
+// AdherenceDTO represents expected days and how many of them were fulfilled
+type AdherenceDTO struct {
+	Scheduled int     `json:"scheduled"`
+	Fulfilled int     `json:"fulfilled"`
+	Rate      float64 `json:"rate"`
+}
+
+// AtRiskHabitDTO represents a habit whose streak breaks unless it is done today
+type AtRiskHabitDTO struct {
+	HabitID       string  `json:"habit_id"`
+	Name          string  `json:"name"`
+	Color         string  `json:"color"`
+	CurrentStreak int     `json:"current_streak"`
+	Count         int     `json:"count"`
+	TargetCount   int     `json:"target_count"`
+	Progress      float64 `json:"progress"`
+}
+
+// CategoryTotalsDTO represents the totals of the habits that share a category
+type CategoryTotalsDTO struct {
+	Category         string       `json:"category"`
+	Habits           int          `json:"habits"`
+	ActiveHabits     int          `json:"active_habits"`
+	TotalCompletions int          `json:"total_completions"`
+	CurrentStreaks   int          `json:"current_streaks"`
+	Week             AdherenceDTO `json:"week"`
+}
+
+// OverviewResponseDTO represents the dashboard summary across all of a user's habits
+type OverviewResponseDTO struct {
+	Date       string              `json:"date"`
+	Today      AdherenceDTO        `json:"today"`
+	Week       AdherenceDTO        `json:"week"`
+	Month      AdherenceDTO        `json:"month"`
+	AtRisk     []AtRiskHabitDTO    `json:"at_risk"`
+	Categories []CategoryTotalsDTO `json:"categories"`
+}
+
+// CorrelationsQueryDTO represents query parameters for habit correlations
+type CorrelationsQueryDTO struct {
+	From       *string `json:"from" validate:"omitempty,datetime=2006-01-02"`
+	To         *string `json:"to" validate:"omitempty,datetime=2006-01-02"`
+	MinSamples *int    `json:"min_samples" validate:"omitempty,min=1,max=366"`
+}
+
+// HabitCorrelationDTO represents how often two habits are fulfilled on the same day
+type HabitCorrelationDTO struct {
+	HabitA        HabitSummaryDTO `json:"habit_a"`
+	HabitB        HabitSummaryDTO `json:"habit_b"`
+	Samples       int             `json:"samples"`
+	FulfilledA    int             `json:"fulfilled_a"`
+	FulfilledB    int             `json:"fulfilled_b"`
+	FulfilledBoth int             `json:"fulfilled_both"`
+	ConfidenceAB  float64         `json:"confidence_a_to_b"`
+	ConfidenceBA  float64         `json:"confidence_b_to_a"`
+	Lift          float64         `json:"lift"`
+}
+
+// HabitSummaryDTO identifies a habit inside aggregate responses
+type HabitSummaryDTO struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// CorrelationsResponseDTO represents the pairwise correlations between a user's habits
+type CorrelationsResponseDTO struct {
+	From         string                `json:"from"`
+	To           string                `json:"to"`
+	MinSamples   int                   `json:"min_samples"`
+	Correlations []HabitCorrelationDTO `json:"correlations"`
+}
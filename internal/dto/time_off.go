@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// CreateSkipDTO represents the request to skip a habit on a day
+type CreateSkipDTO struct {
+	Date   string  `json:"date" validate:"required,datetime=2006-01-02"`
+	Reason *string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// SkipResponseDTO represents a habit skip in API responses
+type SkipResponseDTO struct {
+	ID        string    `json:"id"`
+	HabitID   string    `json:"habit_id"`
+	SkipDate  time.Time `json:"skip_date"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreatePauseDTO represents the request to pause all habits over a date range
+type CreatePauseDTO struct {
+	StartDate string  `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string  `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Reason    *string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// PauseResponseDTO represents a pause in API responses
+type PauseResponseDTO struct {
+	ID        string    `json:"id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// TimerSessionResponseDTO represents a habit's timer session in API responses
+type TimerSessionResponseDTO struct {
+	ID             string     `json:"id"`
+	HabitID        string     `json:"habit_id"`
+	Status         string     `json:"status"`
+	StartedAt      time.Time  `json:"started_at"`
+	ResumedAt      *time.Time `json:"resumed_at"`
+	ElapsedSeconds int        `json:"elapsed_seconds"`
+	StoppedAt      *time.Time `json:"stopped_at"`
+	CompletionID   *string    `json:"completion_id"`
+}
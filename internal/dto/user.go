@@ -0,0 +1,6 @@
+package dto
+
+// UpdateMeDTO represents the request to update the authenticated user's settings
+type UpdateMeDTO struct {
+	Timezone *string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"math"
 	"strings"
 	"time"
 )
@@ -14,12 +15,19 @@ type HabitCompletion struct {
 	CompletedAt    time.Time `json:"completed_at"`
 	CompletionDate time.Time `json:"completion_date"`
 	Count          int       `json:"count"`
-	Notes          *string   `json:"notes"`
-	CreatedAt      time.Time `json:"created_at"`
+	// For measurable habits: the amount logged on the day, in the habit's unit
+	Value       *float64 `json:"value"`
+	TargetCount int      `json:"target_count"`
+	// For measurable habits: the habit's target value at the time of logging
+	TargetValue *float64  `json:"target_value"`
+	Notes       *string   `json:"notes"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
-// NewHabitCompletion creates a new habit completion record
-func NewHabitCompletion(id, habitID, userID string, completionDate time.Time, count int, notes *string) (*HabitCompletion, error) {
+// NewHabitCompletion creates a new habit completion record. targetCount and targetValue
+// are the habit's targets at the time of logging and decide whether the day counts as
+// fulfilled. value and targetValue are only set for measurable habits.
+func NewHabitCompletion(id, habitID, userID string, completionDate time.Time, count int, value *float64, targetCount int, targetValue *float64, notes *string) (*HabitCompletion, error) {
 	now := time.Now()
 
 	completion := &HabitCompletion{
@@ -29,6 +37,9 @@ func NewHabitCompletion(id, habitID, userID string, completionDate time.Time, co
 		CompletedAt:    now,
 		CompletionDate: completionDate,
 		Count:          count,
+		Value:          value,
+		TargetCount:    targetCount,
+		TargetValue:    targetValue,
 		Notes:          notes,
 		CreatedAt:      now,
 	}
@@ -49,6 +60,35 @@ func (c *HabitCompletion) SetNotes(notes string) {
 	}
 }
 
+// IsMeasured reports whether the completion logs a value against a target value
+// instead of a count
+func (c *HabitCompletion) IsMeasured() bool {
+	return c.TargetValue != nil
+}
+
+// IsFulfilled reports whether the logged count, or value for measurable habits, reaches
+// the target for the day. A completion below the target only records partial progress.
+func (c *HabitCompletion) IsFulfilled() bool {
+	if c.IsMeasured() {
+		return c.Value != nil && *c.Value >= *c.TargetValue
+	}
+	return c.Count >= c.TargetCount
+}
+
+// Progress returns the share of the daily target that was reached, capped at 1
+func (c *HabitCompletion) Progress() float64 {
+	if c.IsMeasured() {
+		if c.Value == nil || *c.TargetValue <= 0 {
+			return 0
+		}
+		return min(*c.Value / *c.TargetValue, 1)
+	}
+	if c.TargetCount <= 0 {
+		return 0
+	}
+	return min(float64(c.Count)/float64(c.TargetCount), 1)
+}
+
 // ValidateCompletion validates a habit completion
 func ValidateCompletion(completion *HabitCompletion) error {
 	if completion.ID == "" {
@@ -63,9 +103,35 @@ func ValidateCompletion(completion *HabitCompletion) error {
 	if completion.Count <= 0 {
 		return errors.New("count must be positive")
 	}
+	if completion.TargetCount <= 0 {
+		return errors.New("target count must be positive")
+	}
 	if completion.CompletionDate.IsZero() {
 		return errors.New("completion date is required")
 	}
+	if completion.TargetValue != nil {
+		if !isPositiveValue(*completion.TargetValue) {
+			return errors.New("target value must be positive")
+		}
+		if completion.Value == nil {
+			return errors.New("value is required for measurable habits")
+		}
+	}
+	if completion.Value != nil {
+		if completion.TargetValue == nil {
+			return errors.New("value is only allowed for measurable habits")
+		}
+		if !isPositiveValue(*completion.Value) || *completion.Value > MaxMeasuredValue {
+			return errors.New("value must be positive and at most 1000000")
+		}
+	}
 
 	return nil
 }
+
+// MaxMeasuredValue bounds the values and target values of measurable habits
+const MaxMeasuredValue = 1_000_000
+
+func isPositiveValue(value float64) bool {
+	return value > 0 && !math.IsInf(value, 0) && !math.IsNaN(value)
+}
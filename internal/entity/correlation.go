@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// HabitCorrelation describes how often two habits are fulfilled on the same day. Only days
+// on which both habits were expected are sampled. Quit habits count as fulfilled on the
+// days without a slip.
+type HabitCorrelation struct {
+	HabitA *Habit
+	HabitB *Habit
+	// Days on which both habits were expected
+	Samples int
+	// Sampled days on which HabitA, HabitB or both were fulfilled
+	FulfilledA    int
+	FulfilledB    int
+	FulfilledBoth int
+	// Share of HabitA's fulfilled days on which HabitB was fulfilled too, and vice versa
+	ConfidenceAB float64
+	ConfidenceBA float64
+	// How much more likely the habits are fulfilled together than if they were
+	// independent. 1 means no relation, higher means they go together.
+	Lift float64
+}
+
+// CorrelationReport holds the correlations between a user's habits over a date range
+type CorrelationReport struct {
+	From         time.Time
+	To           time.Time
+	MinSamples   int
+	Correlations []*HabitCorrelation
+}
+
+// CalculateCorrelations compares every pair of habits between from and to, inclusive.
+// Pairs with fewer than minSamples shared days, or where either habit was never
+// fulfilled, are left out. Days off of either habit aren't shared days. The result is
+// ordered by lift, strongest first.
+func CalculateCorrelations(habits []*Habit, completions []*HabitCompletion, timeOff *TimeOff, from, to time.Time, minSamples int) []*HabitCorrelation {
+	from, to = DateOnly(from), DateOnly(to)
+
+	expected := make([]map[time.Time]bool, len(habits))
+	marked := make([]map[time.Time]bool, len(habits))
+	for i, habit := range habits {
+		expected[i] = make(map[time.Time]bool)
+		marked[i] = habit.markedDays(completions)
+
+		start := later(from, habit.trackingStart(completions))
+		for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+			if habit.expectedOn(day, timeOff) {
+				expected[i][day] = true
+			}
+		}
+	}
+
+	correlations := []*HabitCorrelation{}
+	for i := range habits {
+		for j := i + 1; j < len(habits); j++ {
+			correlation := &HabitCorrelation{HabitA: habits[i], HabitB: habits[j]}
+			for day := range expected[i] {
+				if !expected[j][day] {
+					continue
+				}
+				correlation.Samples++
+				keptA := habits[i].succeededOn(day, marked[i])
+				keptB := habits[j].succeededOn(day, marked[j])
+				if keptA {
+					correlation.FulfilledA++
+				}
+				if keptB {
+					correlation.FulfilledB++
+				}
+				if keptA && keptB {
+					correlation.FulfilledBoth++
+				}
+			}
+
+			if correlation.Samples < minSamples || correlation.FulfilledA == 0 || correlation.FulfilledB == 0 {
+				continue
+			}
+
+			correlation.ConfidenceAB = ratio(correlation.FulfilledBoth, correlation.FulfilledA)
+			correlation.ConfidenceBA = ratio(correlation.FulfilledBoth, correlation.FulfilledB)
+			lift := float64(correlation.FulfilledBoth*correlation.Samples) / float64(correlation.FulfilledA*correlation.FulfilledB)
+			correlation.Lift = math.Round(lift*10000) / 10000
+
+			correlations = append(correlations, correlation)
+		}
+	}
+
+	slices.SortStableFunc(correlations, func(a, b *HabitCorrelation) int {
+		switch {
+		case a.Lift > b.Lift:
+			return -1
+		case a.Lift < b.Lift:
+			return 1
+		default:
+			return b.Samples - a.Samples
+		}
+	})
+
+	return correlations
+}
@@ -0,0 +1,111 @@
+package entity
+
+import "time"
+
+// DueHabit is a habit that is expected on a given day, together with that day's progress
+type DueHabit struct {
+	Habit      *Habit
+	Date       time.Time
+	Completion *HabitCompletion
+	// For quota habits: fulfilled days of the period containing Date, up to and including Date
+	PeriodCompleted int
+}
+
+// Count returns the amount logged on the day
+func (d *DueHabit) Count() int {
+	if d.Completion == nil {
+		return 0
+	}
+	return d.Completion.Count
+}
+
+// TargetCount returns the target that applies to the day
+func (d *DueHabit) TargetCount() int {
+	if d.Completion == nil {
+		return d.Habit.TargetCount
+	}
+	return d.Completion.TargetCount
+}
+
+// Value returns the value logged on the day by a measurable habit
+func (d *DueHabit) Value() *float64 {
+	if d.Completion == nil {
+		return nil
+	}
+	return d.Completion.Value
+}
+
+// TargetValue returns the target value that applies to the day for measurable habits
+func (d *DueHabit) TargetValue() *float64 {
+	if d.Completion == nil {
+		return d.Habit.TargetValue
+	}
+	return d.Completion.TargetValue
+}
+
+// Progress returns the share of the day's target that was reached. A quit habit is at
+// full progress until a slip is logged.
+func (d *DueHabit) Progress() float64 {
+	if d.Habit.IsQuit() {
+		if d.Completion == nil {
+			return 1
+		}
+		return 0
+	}
+	if d.Completion == nil {
+		return 0
+	}
+	return d.Completion.Progress()
+}
+
+// IsFulfilled reports whether the day's target was reached, or for quit habits whether
+// the day is still clean
+func (d *DueHabit) IsFulfilled() bool {
+	if d.Habit.IsQuit() {
+		return d.Completion == nil
+	}
+	return d.Completion != nil && d.Completion.IsFulfilled()
+}
+
+// DueOn decides whether the habit is due on date and returns it with the day's progress.
+// completions must contain the habit's completions from the start of the quota period
+// containing date; completions of other habits and later days are ignored. Quota habits
+// stay due until enough days of the period were fulfilled, and also on any day that has
+// progress logged. Habits are never due on their days off.
+func (h *Habit) DueOn(date time.Time, completions []*HabitCompletion, timeOff *TimeOff) (*DueHabit, bool) {
+	date = DateOnly(date)
+	if !h.IsActive || timeOff.Covers(h.ID, date) {
+		return nil, false
+	}
+
+	due := &DueHabit{Habit: h, Date: date}
+
+	// Past days are judged by the schedule that was in force on them
+	schedule := h.ScheduleOn(date)
+	periodStart := schedule.PeriodStart(date)
+	for _, completion := range completions {
+		if completion.HabitID != h.ID {
+			continue
+		}
+		day := DateOnly(completion.CompletionDate)
+		if day.Equal(date) {
+			due.Completion = completion
+		}
+		if IsPeriodQuota(schedule.Frequency) && !day.Before(periodStart) && !day.After(date) && completion.IsFulfilled() && !timeOff.Covers(h.ID, day) {
+			due.PeriodCompleted++
+		}
+	}
+
+	if IsPeriodQuota(schedule.Frequency) {
+		if due.Completion == nil && schedule.PeriodTarget != nil && due.PeriodCompleted >= *schedule.PeriodTarget {
+			return nil, false
+		}
+		return due, true
+	}
+
+	if !schedule.occursOn(date, h.CreatedAt, h.recurrenceRule(schedule)) {
+		return nil, false
+	}
+
+	return due, true
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidEmailToken = errors.New("link is invalid or has expired")
+
+// EmailTokenPurpose is what an emailed token lets its holder do
+type EmailTokenPurpose string
+
+const (
+	EmailTokenVerification  EmailTokenPurpose = "email_verification"
+	EmailTokenPasswordReset EmailTokenPurpose = "password_reset"
+	EmailTokenMagicLink     EmailTokenPurpose = "magic_link"
+)
+
+// TTL is how long a token of the purpose can be used after it was sent
+func (p EmailTokenPurpose) TTL() time.Duration {
+	switch p {
+	case EmailTokenVerification:
+		return 24 * time.Hour
+	case EmailTokenPasswordReset:
+		return time.Hour
+	default:
+		return 15 * time.Minute
+	}
+}
+
+// EmailToken is a single use token sent to the user's email address. Only its hash is
+// stored, so the token can't be read back from the database.
+type EmailToken struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Purpose   EmailTokenPurpose `json:"purpose"`
+	TokenHash string            `json:"-"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	UsedAt    *time.Time        `json:"used_at"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// NewEmailToken records a token with the given hash that expires after the purpose's TTL
+func NewEmailToken(id, userID string, purpose EmailTokenPurpose, tokenHash string, now time.Time) *EmailToken {
+	return &EmailToken{
+		ID:        id,
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(purpose.TTL()),
+		CreatedAt: now,
+	}
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// FreezeEarnDays is the number of consecutive fulfilled days that earn a streak freeze token
+const FreezeEarnDays = 7
+
+const (
+	FreezeEarned = "earned"
+	FreezeUsed   = "used"
+)
+
+// FreezeEvent records a streak freeze token being earned or used on a day
+type FreezeEvent struct {
+	Date time.Time
+	// FreezeEarned or FreezeUsed
+	Type string
+	// Token balance right after the event
+	Balance int
+	// Streak that earned the token, or that the token kept alive
+	Streak int
+}
+
+// FreezeLedger holds a habit's streak freeze token balance and the events that led to it.
+// Tokens are derived from the completion history, so the ledger always agrees with the
+// streaks computed from the same history.
+type FreezeLedger struct {
+	Balance  int
+	EarnDays int
+	Events   []FreezeEvent
+}
+
+func (l *FreezeLedger) add(day time.Time, eventType string, streak int) {
+	if eventType == FreezeEarned {
+		l.Balance++
+	} else {
+		l.Balance--
+	}
+	l.Events = append(l.Events, FreezeEvent{Date: day, Type: eventType, Balance: l.Balance, Streak: streak})
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 	"slices"
 	"strings"
 	"time"
@@ -14,26 +15,50 @@ type TargetDays struct {
 	Days []any `json:"days"`
 }
 
+// Schedule describes when a habit is due and what counts as done on a given day
+type Schedule struct {
+	Frequency   string      `json:"frequency"`
+	TargetCount int         `json:"target_count"`
+	TargetDays  *TargetDays `json:"target_days"`
+	// For times_per_week and times_per_month habits: how many fulfilled days make a period
+	PeriodTarget *int `json:"period_target"`
+	// For interval habits: the habit is due every IntervalDays days counted from AnchorDate
+	IntervalDays *int `json:"interval_days"`
+	// Start date of interval and custom habits
+	AnchorDate *time.Time `json:"anchor_date"`
+	// For custom habits: an RFC 5545 RRULE such as "FREQ=MONTHLY;BYDAY=1MO"
+	Recurrence *string `json:"recurrence"`
+	// For measurable habits: the value that fulfills a day, in the habit's unit
+	TargetValue *float64 `json:"target_value"`
+}
+
 type Habit struct {
-	ID               string      `json:"id"`
-	UserID           string      `json:"user_id"`
-	Name             string      `json:"name"`
-	Description      *string     `json:"description"`
-	Motivation       *string     `json:"motivation"`
-	Color            string      `json:"color"`
-	Category         *string     `json:"category"`
-	Frequency        string      `json:"frequency"`
-	TargetCount      int         `json:"target_count"`
-	TargetDays       *TargetDays `json:"target_days"`
-	CurrentStreak    int         `json:"current_streak"`
-	BestStreak       int         `json:"best_streak"`
-	TotalCompletions int         `json:"total_completions"`
-	IsActive         bool        `json:"is_active"`
-	CreatedAt        time.Time   `json:"created_at"`
-	UpdatedAt        time.Time   `json:"updated_at"`
-}
-
-func NewHabit(id, userID string, name, frequency string, targetCount int, description, motivation, category *string, targetDays *TargetDays, color string) (*Habit, error) {
+	ID          string  `json:"id"`
+	UserID      string  `json:"user_id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Motivation  *string `json:"motivation"`
+	Color       string  `json:"color"`
+	Category    *string `json:"category"`
+	// "count" habits tally how often they were done, "measurable" habits log a value,
+	// "duration" habits log minutes, usually with a timer, and "quit" habits log slips of a
+	// habit the user is trying to break
+	Kind string  `json:"kind"`
+	Unit *string `json:"unit"`
+	Schedule
+	// Earlier schedules of the habit, oldest first. The latest revision matches Schedule.
+	ScheduleHistory  []*ScheduleRevision `json:"schedule_history"`
+	CurrentStreak    int                 `json:"current_streak"`
+	BestStreak       int                 `json:"best_streak"`
+	TotalCompletions int                 `json:"total_completions"`
+	IsActive         bool                `json:"is_active"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+	// Parsed RRULEs of the habit's custom schedules, by rule
+	recurrenceRules map[string]*RecurrenceRule
+}
+
+func NewHabit(id, userID string, name, kind string, schedule Schedule, description, motivation, category, unit *string, color string, today time.Time) (*Habit, error) {
 	now := time.Now()
 	habit := &Habit{
 		ID:               id,
@@ -43,9 +68,9 @@ func NewHabit(id, userID string, name, frequency string, targetCount int, descri
 		Motivation:       motivation,
 		Color:            color,
 		Category:         category,
-		Frequency:        frequency,
-		TargetCount:      targetCount,
-		TargetDays:       targetDays,
+		Kind:             kind,
+		Unit:             unit,
+		Schedule:         schedule,
 		CurrentStreak:    0,
 		BestStreak:       0,
 		TotalCompletions: 0,
@@ -54,6 +79,17 @@ func NewHabit(id, userID string, name, frequency string, targetCount int, descri
 		UpdatedAt:        now,
 	}
 
+	if habit.Kind == "" {
+		habit.Kind = "count"
+	}
+
+	// Interval and custom habits without an explicit anchor start on the day they are
+	// created, today in the user's timezone
+	if usesAnchorDate(habit.Frequency) && habit.AnchorDate == nil {
+		anchor := DateOnly(today)
+		habit.AnchorDate = &anchor
+	}
+
 	if err := Validate(habit); err != nil {
 		return nil, err
 	}
@@ -118,12 +154,139 @@ func (h *Habit) Activate() {
 	h.IsActive = true
 }
 
-var validFrequencies = []string{"daily", "weekly", "monthly"}
+var validKinds = []string{"count", "measurable", "quit", "duration"}
+
+// ErrKindChangeWithHistory is returned when changing the kind of a habit that has
+// completions or timer sessions, which the new kind would read differently, e.g.
+// turning check-ins into slips
+var ErrKindChangeWithHistory = errors.New("kind can't be changed once the habit has history")
+
+// IsDuration reports whether the habit's count and target count are minutes
+func (h *Habit) IsDuration() bool {
+	return h.Kind == "duration"
+}
+
+// IsQuit reports whether the habit is one the user wants to break, so that every entry
+// records a slip and the days without one are the successes
+func (h *Habit) IsQuit() bool {
+	return h.Kind == "quit"
+}
+
+// IsMeasurable reports whether the habit logs a decimal value in its unit instead of a count
+func (h *Habit) IsMeasurable() bool {
+	return h.Kind == "measurable"
+}
+
+func validateKind(kind, frequency string, unit *string, targetValue *float64) error {
+	if !slices.Contains(validKinds, kind) {
+		return errors.New("invalid kind")
+	}
+
+	// A slip can happen on any day, so quit habits are tracked daily
+	if kind == "quit" && frequency != "daily" {
+		return errors.New("quit habits must have a daily frequency")
+	}
+
+	if kind != "measurable" {
+		if unit != nil || targetValue != nil {
+			return errors.New("unit and target value are only allowed for measurable habits")
+		}
+		return nil
+	}
+
+	if unit == nil || strings.TrimSpace(*unit) == "" {
+		return errors.New("unit is required for measurable habits")
+	}
+	if targetValue == nil {
+		return errors.New("target value is required for measurable habits")
+	}
+	if !isPositiveValue(*targetValue) || *targetValue > MaxMeasuredValue {
+		return errors.New("target value must be positive and at most 1000000")
+	}
+
+	return nil
+}
+
+var validFrequencies = []string{"daily", "weekly", "monthly", "times_per_week", "times_per_month", "interval", "custom"}
 
 func isValidFrequency(frequency string) bool {
 	return slices.Contains(validFrequencies, frequency)
 }
 
+// IsPeriodQuota reports whether the frequency asks for a number of days per week or month
+// instead of specific days
+func IsPeriodQuota(frequency string) bool {
+	return frequency == "times_per_week" || frequency == "times_per_month"
+}
+
+func validatePeriodTarget(frequency string, periodTarget *int) error {
+	if !IsPeriodQuota(frequency) {
+		if periodTarget != nil {
+			return errors.New("period target is only allowed for times_per_week and times_per_month habits")
+		}
+		return nil
+	}
+
+	if periodTarget == nil {
+		return errors.New("period target is required for times_per_week and times_per_month habits")
+	}
+
+	maxTarget := 7
+	if frequency == "times_per_month" {
+		maxTarget = 28
+	}
+	if *periodTarget < 1 || *periodTarget > maxTarget {
+		return fmt.Errorf("period target must be between 1 and %d", maxTarget)
+	}
+
+	return nil
+}
+
+// usesAnchorDate reports whether the frequency counts its schedule from an anchor date
+func usesAnchorDate(frequency string) bool {
+	return frequency == "interval" || frequency == "custom"
+}
+
+func validateInterval(frequency string, intervalDays *int, anchorDate *time.Time) error {
+	if !usesAnchorDate(frequency) && anchorDate != nil {
+		return errors.New("anchor date is only allowed for interval and custom habits")
+	}
+
+	if frequency != "interval" {
+		if intervalDays != nil {
+			return errors.New("interval days are only allowed for interval habits")
+		}
+		return nil
+	}
+
+	if intervalDays == nil || anchorDate == nil {
+		return errors.New("interval days and anchor date are required for interval habits")
+	}
+	if *intervalDays < 1 || *intervalDays > 365 {
+		return errors.New("interval days must be between 1 and 365")
+	}
+
+	return nil
+}
+
+func validateRecurrence(frequency string, recurrence *string, anchorDate *time.Time) error {
+	if frequency != "custom" {
+		if recurrence != nil {
+			return errors.New("recurrence is only allowed for custom habits")
+		}
+		return nil
+	}
+
+	if recurrence == nil || anchorDate == nil {
+		return errors.New("recurrence and anchor date are required for custom habits")
+	}
+	if _, err := ParseRecurrenceRule(*recurrence); err != nil {
+		return fmt.Errorf("invalid recurrence: %w", err)
+	}
+
+	return nil
+}
+
 func isValidColor(color string) bool {
 	// Check if color is a valid hex color code
 	return strings.HasPrefix(color, "#") && len(color) == 7
@@ -138,6 +301,10 @@ func validateTargetDays(frequency string, days *TargetDays) error {
 	switch frequency {
 	case "daily":
 		return nil
+	case "times_per_week", "times_per_month", "interval", "custom":
+		if len(days.Days) > 0 {
+			return errors.New("target days are not allowed for " + frequency + " habits")
+		}
 	case "weekly":
 		for _, day := range days.Days {
 			dayStr, ok := day.(string)
@@ -196,6 +363,18 @@ func Validate(habit *Habit) error {
 	if err := validateTargetDays(habit.Frequency, habit.TargetDays); err != nil {
 		return err
 	}
+	if err := validatePeriodTarget(habit.Frequency, habit.PeriodTarget); err != nil {
+		return err
+	}
+	if err := validateInterval(habit.Frequency, habit.IntervalDays, habit.AnchorDate); err != nil {
+		return err
+	}
+	if err := validateRecurrence(habit.Frequency, habit.Recurrence, habit.AnchorDate); err != nil {
+		return err
+	}
+	if err := validateKind(habit.Kind, habit.Frequency, habit.Unit, habit.TargetValue); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,321 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// CompletionRateWindows are the trailing windows, in days, that habit statistics report
+var CompletionRateWindows = []int{7, 30, 90, 365}
+
+// HabitStats summarizes a habit's completion history
+type HabitStats struct {
+	Rates []CompletionRate
+	// Average count logged per completion
+	AverageCount float64
+	// For measurable habits: sum and average of the values logged per completion
+	TotalValue   float64
+	AverageValue float64
+	// Weekday with the most fulfilled completions, or clean days for quit habits, nil
+	// before the first one
+	BestWeekday *time.Weekday
+	// Longest run of calendar days without any completion since the first one
+	LongestGap int
+	Series     []StatsBucket
+}
+
+// CompletionRate is the share of expected days that were fulfilled over the trailing Days
+type CompletionRate struct {
+	Days      int
+	Scheduled int
+	Fulfilled int
+	Rate      float64
+}
+
+// StatsBucket aggregates one week or month of a habit's time series
+type StatsBucket struct {
+	Start time.Time
+	End   time.Time
+	Count int
+	// For measurable habits: sum of the values logged in the bucket
+	Value     float64
+	Scheduled int
+	Fulfilled int
+	Rate      float64
+}
+
+// Adherence counts the days between from and to, inclusive, on which the habit was
+// expected and how many of them reached the target. Days before the habit was tracked
+// are not expected, and today only counts once its outcome is settled since it is still
+// in progress: once fulfilled, or for quit habits once a slip was logged. Quota habits
+// expect up to PeriodTarget days of each period, and the current period only expects
+// what has already been done. Each day is judged by the schedule in force on it, and
+// days off in timeOff are neither expected nor fulfilled.
+func (h *Habit) Adherence(completions []*HabitCompletion, timeOff *TimeOff, from, to, today time.Time) (scheduled, fulfilled int) {
+	from, to, today = DateOnly(from), DateOnly(to), DateOnly(today)
+	if to.After(today) {
+		to = today
+	}
+
+	marked := h.markedDays(completions)
+	if start := h.trackingStart(completions); from.Before(start) {
+		from = start
+	}
+	if from.After(to) {
+		return 0, 0
+	}
+
+	for _, span := range h.scheduleSpans(from, to) {
+		if IsPeriodQuota(span.Frequency) {
+			spanScheduled, spanFulfilled := h.periodAdherence(span, marked, timeOff, today)
+			scheduled += spanScheduled
+			fulfilled += spanFulfilled
+			continue
+		}
+
+		for day := span.From; !day.After(span.To); day = day.AddDate(0, 0, 1) {
+			if !span.occursOn(day, h.CreatedAt, span.rule) || timeOff.Covers(h.ID, day) {
+				continue
+			}
+			if day.Equal(today) && !marked[day] {
+				continue
+			}
+			scheduled++
+			if h.succeededOn(day, marked) {
+				fulfilled++
+			}
+		}
+	}
+
+	return scheduled, fulfilled
+}
+
+func (h *Habit) periodAdherence(span scheduleSpan, done map[time.Time]bool, timeOff *TimeOff, today time.Time) (scheduled, fulfilled int) {
+	if span.PeriodTarget == nil {
+		return 0, 0
+	}
+
+	for day := span.From; !day.After(span.To); {
+		periodStart, periodEnd := span.period(day)
+		start := later(periodStart, span.From)
+		end := earlier(periodEnd, span.To)
+		day = periodEnd.AddDate(0, 0, 1)
+
+		got := 0
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			if done[d] && !timeOff.Covers(h.ID, d) {
+				got++
+			}
+		}
+
+		// A window that only covers part of a period, or one with days off, can't expect
+		// more days than it has available
+		expected := min(*span.PeriodTarget, h.availableDays(start, end, timeOff))
+		met := min(got, expected)
+		if !periodEnd.Before(today) && met < expected {
+			expected = met
+		}
+
+		scheduled += expected
+		fulfilled += met
+	}
+
+	return scheduled, fulfilled
+}
+
+// CalculateStats builds the habit's statistics from its completion history as of today.
+// The time series covers the last buckets weeks (Monday to Sunday) or, when monthly is
+// set, months, ending with the current one. Days off in timeOff don't count toward the
+// completion rates.
+func (h *Habit) CalculateStats(completions []*HabitCompletion, timeOff *TimeOff, today time.Time, monthly bool, buckets int) *HabitStats {
+	today = DateOnly(today)
+	stats := &HabitStats{}
+
+	for _, days := range CompletionRateWindows {
+		scheduled, fulfilled := h.Adherence(completions, timeOff, today.AddDate(0, 0, -(days-1)), today, today)
+		stats.Rates = append(stats.Rates, CompletionRate{
+			Days:      days,
+			Scheduled: scheduled,
+			Fulfilled: fulfilled,
+			Rate:      ratio(fulfilled, scheduled),
+		})
+	}
+
+	var total, logged int
+	var totalValue float64
+	var weekdays [7]int
+	logDays := make(map[time.Time]bool)
+	for _, completion := range completions {
+		if completion.HabitID != h.ID {
+			continue
+		}
+		day := DateOnly(completion.CompletionDate)
+		if day.After(today) {
+			continue
+		}
+		total += completion.Count
+		logged++
+		if completion.Value != nil {
+			totalValue += *completion.Value
+		}
+		logDays[day] = true
+		if !h.IsQuit() && completion.IsFulfilled() {
+			weekdays[day.Weekday()]++
+		}
+	}
+
+	// Quit habits succeed on the days without a slip
+	if h.IsQuit() {
+		for day := h.trackingStart(completions); day.Before(today); day = day.AddDate(0, 0, 1) {
+			if !logDays[day] && !timeOff.Covers(h.ID, day) {
+				weekdays[day.Weekday()]++
+			}
+		}
+	}
+
+	if logged > 0 {
+		stats.AverageCount = float64(total) / float64(logged)
+		if h.IsMeasurable() {
+			stats.TotalValue = roundValue(totalValue)
+			stats.AverageValue = roundValue(totalValue / float64(logged))
+		}
+	}
+
+	// Weeks start on Monday, so ties go to the earlier day of the week
+	for i := range 7 {
+		weekday := time.Weekday((i + 1) % 7)
+		if weekdays[weekday] > 0 && (stats.BestWeekday == nil || weekdays[weekday] > weekdays[*stats.BestWeekday]) {
+			stats.BestWeekday = &weekday
+		}
+	}
+
+	stats.LongestGap = longestGap(logDays, today)
+	stats.Series = h.series(completions, timeOff, today, monthly, buckets)
+
+	return stats
+}
+
+func (h *Habit) series(completions []*HabitCompletion, timeOff *TimeOff, today time.Time, monthly bool, buckets int) []StatsBucket {
+	step := Schedule{Frequency: "times_per_week"}
+	if monthly {
+		step.Frequency = "times_per_month"
+	}
+
+	start := step.PeriodStart(today)
+	for range buckets - 1 {
+		if monthly {
+			start = start.AddDate(0, -1, 0)
+		} else {
+			start = start.AddDate(0, 0, -7)
+		}
+	}
+
+	var series []StatsBucket
+	for range buckets {
+		next := step.nextPeriodStart(start)
+		bucket := StatsBucket{Start: start, End: next.AddDate(0, 0, -1)}
+
+		for _, completion := range completions {
+			day := DateOnly(completion.CompletionDate)
+			if completion.HabitID == h.ID && !day.Before(bucket.Start) && !day.After(bucket.End) {
+				bucket.Count += completion.Count
+				if completion.Value != nil {
+					bucket.Value += *completion.Value
+				}
+			}
+		}
+		bucket.Value = roundValue(bucket.Value)
+		bucket.Scheduled, bucket.Fulfilled = h.Adherence(completions, timeOff, bucket.Start, bucket.End, today)
+		bucket.Rate = ratio(bucket.Fulfilled, bucket.Scheduled)
+
+		series = append(series, bucket)
+		start = next
+	}
+
+	return series
+}
+
+// markedDays returns the days whose outcome is settled by a completion: the days that
+// reached the target, or for quit habits the days with a slip
+func (h *Habit) markedDays(completions []*HabitCompletion) map[time.Time]bool {
+	marked := make(map[time.Time]bool)
+	for _, completion := range completions {
+		if completion.HabitID == h.ID && (h.IsQuit() || completion.IsFulfilled()) {
+			marked[DateOnly(completion.CompletionDate)] = true
+		}
+	}
+	return marked
+}
+
+// succeededOn reports whether the habit was kept on day given its markedDays
+func (h *Habit) succeededOn(day time.Time, marked map[time.Time]bool) bool {
+	return marked[day] != h.IsQuit()
+}
+
+// trackingStart is the first day the habit is expected: the day it was created, or an
+// earlier day that was backfilled with a completion
+func (h *Habit) trackingStart(completions []*HabitCompletion) time.Time {
+	start := DateOnly(h.CreatedAt)
+	for _, completion := range completions {
+		if completion.HabitID != h.ID {
+			continue
+		}
+		if day := DateOnly(completion.CompletionDate); day.Before(start) {
+			start = day
+		}
+	}
+	return start
+}
+
+// longestGap returns the longest run of days without activity, from the first active
+// day up to yesterday
+func longestGap(activeDays map[time.Time]bool, today time.Time) int {
+	var first time.Time
+	for day := range activeDays {
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+	}
+	if first.IsZero() {
+		return 0
+	}
+
+	longest, gap := 0, 0
+	for day := first; day.Before(today); day = day.AddDate(0, 0, 1) {
+		if activeDays[day] {
+			gap = 0
+			continue
+		}
+		gap++
+		longest = max(longest, gap)
+	}
+
+	return longest
+}
+
+// ratio returns part/whole rounded to four decimals, or 0 when whole is 0
+func ratio(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
+
+// roundValue rounds a sum of measured values to four decimals to hide float drift
+func roundValue(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// HeatmapDay aggregates a user's completions on a single day
+type HeatmapDay struct {
+	Date time.Time
+	// Sum of the counts logged on the day
+	Count int
+	// Sum of the values logged on the day by measurable habits
+	Value float64
+	// Number of habits expected on the day that were kept: their target was reached, or
+	// for quit habits, no slip was logged
+	Fulfilled int
+	// Number of habits that were expected on the day
+	Scheduled int
+	// Number of quit habits with a slip logged on the day
+	Slips int
+	// Habits whose target was reached on the day, whether or not they were expected
+	FulfilledHabitIDs []string
+	// Quit habits with a slip logged on the day, whether or not they were expected
+	SlipHabitIDs []string
+}
+
+// IsEmpty reports whether nothing was logged or expected on the day
+func (d *HeatmapDay) IsEmpty() bool {
+	return d.Count == 0 && d.Fulfilled == 0 && d.Scheduled == 0 && d.Slips == 0
+}
+
+// Heatmap is a user's day-by-day activity between two dates, inclusive
+type Heatmap struct {
+	From time.Time
+	To   time.Time
+	Days []*HeatmapDay
+}
@@ -0,0 +1,116 @@
+package entity
+
+import "time"
+
+// UncategorizedCategory groups habits without a category in the overview
+const UncategorizedCategory = "uncategorized"
+
+// Overview summarizes all of a user's habits as of a day
+type Overview struct {
+	Date  time.Time
+	Today Adherence
+	Week  Adherence
+	Month Adherence
+	// Due habits that still need to be done today to keep their streak
+	AtRisk     []*DueHabit
+	Categories []*CategoryTotals
+}
+
+// Adherence counts expected days and the days among them that reached the target
+type Adherence struct {
+	Scheduled int
+	Fulfilled int
+}
+
+// Rate returns the share of expected days that were fulfilled
+func (a Adherence) Rate() float64 {
+	return ratio(a.Fulfilled, a.Scheduled)
+}
+
+func (a *Adherence) add(scheduled, fulfilled int) {
+	a.Scheduled += scheduled
+	a.Fulfilled += fulfilled
+}
+
+// CategoryTotals aggregates the habits that share a category
+type CategoryTotals struct {
+	Category         string
+	Habits           int
+	ActiveHabits     int
+	TotalCompletions int
+	CurrentStreaks   int
+	// This week's adherence of the category's active habits
+	Week Adherence
+}
+
+// IsAtRisk reports whether skipping the rest of the day would break the habit's streak.
+// Quota habits are only at risk once the days left in the period are just enough to
+// reach the target. Quit habits keep their streak by doing nothing, so they never are.
+func (d *DueHabit) IsAtRisk() bool {
+	if d.Habit.IsQuit() || d.Habit.CurrentStreak == 0 || d.IsFulfilled() {
+		return false
+	}
+
+	schedule := d.Habit.ScheduleOn(d.Date)
+	if IsPeriodQuota(schedule.Frequency) {
+		if schedule.PeriodTarget == nil {
+			return false
+		}
+		needed := *schedule.PeriodTarget - d.PeriodCompleted
+		daysLeft := daysBetween(d.Date, schedule.nextPeriodStart(schedule.PeriodStart(d.Date)))
+		return needed > 0 && needed >= daysLeft
+	}
+
+	return true
+}
+
+// BuildOverview summarizes habits as of today. completions and timeOff must cover at
+// least the current week and month; completions outside them are ignored.
+func BuildOverview(habits []*Habit, completions []*HabitCompletion, timeOff *TimeOff, today time.Time) *Overview {
+	today = DateOnly(today)
+	weekStart := Schedule{Frequency: "times_per_week"}.PeriodStart(today)
+	monthStart := Schedule{Frequency: "times_per_month"}.PeriodStart(today)
+
+	overview := &Overview{Date: today, AtRisk: []*DueHabit{}, Categories: []*CategoryTotals{}}
+	categories := make(map[string]*CategoryTotals)
+
+	for _, habit := range habits {
+		name := UncategorizedCategory
+		if habit.Category != nil && *habit.Category != "" {
+			name = *habit.Category
+		}
+		totals, ok := categories[name]
+		if !ok {
+			totals = &CategoryTotals{Category: name}
+			categories[name] = totals
+			overview.Categories = append(overview.Categories, totals)
+		}
+
+		totals.Habits++
+		totals.TotalCompletions += habit.TotalCompletions
+		totals.CurrentStreaks += habit.CurrentStreak
+
+		if !habit.IsActive {
+			continue
+		}
+		totals.ActiveHabits++
+
+		if due, ok := habit.DueOn(today, completions, timeOff); ok {
+			fulfilled := 0
+			if due.IsFulfilled() {
+				fulfilled = 1
+			}
+			overview.Today.add(1, fulfilled)
+			if due.IsAtRisk() {
+				overview.AtRisk = append(overview.AtRisk, due)
+			}
+		}
+
+		weekScheduled, weekFulfilled := habit.Adherence(completions, timeOff, weekStart, today, today)
+		overview.Week.add(weekScheduled, weekFulfilled)
+		totals.Week.add(weekScheduled, weekFulfilled)
+		overview.Month.add(habit.Adherence(completions, timeOff, monthStart, today, today))
+	}
+
+	return overview
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// RefreshTokenTTL is how long a refresh token can be exchanged after it was issued
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+var ErrRefreshTokenReused = errors.New("refresh token was already used")
+
+// RefreshToken is the server side record of an issued refresh token. The tokens of a
+// session form a family, FamilyID being the session's ID. Every use replaces the token
+// with a new one of the same family, so a token that comes back after it was used has
+// been copied, and its whole family is revoked.
+type RefreshToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewRefreshToken issues a token of the given family that expires RefreshTokenTTL from now
+func NewRefreshToken(id, userID, familyID string, now time.Time) *RefreshToken {
+	return &RefreshToken{
+		ID:        id,
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+		CreatedAt: now,
+	}
+}
+
+// Rotate returns the token that replaces t within its family
+func (t *RefreshToken) Rotate(id string, now time.Time) *RefreshToken {
+	return NewRefreshToken(id, t.UserID, t.FamilyID, now)
+}
+
+// IsUsable reports whether the token can still be exchanged at now
+func (t *RefreshToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshTokenRotate(t *testing.T) {
+	issuedAt := day("2025-03-01")
+	token := NewRefreshToken("token-1", "user-1", "session-1", issuedAt)
+
+	rotatedAt := issuedAt.Add(6 * 24 * time.Hour)
+	next := token.Rotate("token-2", rotatedAt)
+
+	assert.Equal(t, "token-2", next.ID)
+	assert.Equal(t, token.UserID, next.UserID)
+	assert.Equal(t, token.FamilyID, next.FamilyID)
+	assert.Equal(t, rotatedAt, next.CreatedAt)
+	assert.Equal(t, rotatedAt.Add(RefreshTokenTTL), next.ExpiresAt)
+	assert.Nil(t, next.UsedAt)
+	assert.Nil(t, next.RevokedAt)
+}
+
+func TestRefreshTokenIsUsable(t *testing.T) {
+	issuedAt := day("2025-03-01")
+
+	tests := []struct {
+		name   string
+		used   bool
+		revoke bool
+		at     time.Time
+		want   bool
+	}{
+		{name: "fresh token", at: issuedAt.Add(time.Minute), want: true},
+		{name: "just before it expires", at: issuedAt.Add(RefreshTokenTTL - time.Second), want: true},
+		{name: "expired token", at: issuedAt.Add(RefreshTokenTTL)},
+		{name: "used token", used: true, at: issuedAt.Add(time.Minute)},
+		{name: "revoked token", revoke: true, at: issuedAt.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := NewRefreshToken("token-1", "user-1", "session-1", issuedAt)
+			if tt.used {
+				token.UsedAt = &issuedAt
+			}
+			if tt.revoke {
+				token.RevokedAt = &issuedAt
+			}
+
+			assert.Equal(t, tt.want, token.IsUsable(tt.at))
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurrenceRule is an RFC 5545 RRULE restricted to whole days. The rule is evaluated
+// against a start date (DTSTART), which for habits is the anchor date.
+//
+// Supported parts: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL,
+// BYDAY (with ordinals for MONTHLY and YEARLY, e.g. 1MO or -1FR), BYMONTHDAY,
+// BYMONTH and WKST.
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RecurrenceWeekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+// RecurrenceWeekday is a BYDAY entry. N is the ordinal within the month or year
+// (negative values count from the end), or 0 for every such weekday.
+type RecurrenceWeekday struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseRecurrenceRule parses an RRULE value such as "FREQ=MONTHLY;BYDAY=1MO".
+// An optional "RRULE:" prefix is accepted.
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "RRULE:"), "rrule:")
+	if value == "" {
+		return nil, errors.New("recurrence rule is empty")
+	}
+
+	rule := &RecurrenceRule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+
+		if seen[key] {
+			return nil, fmt.Errorf("recurrence rule part %s is repeated", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			if !slices.Contains([]string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}, val) {
+				return nil, fmt.Errorf("unsupported recurrence frequency %q", val)
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			rule.Interval, err = parsePositiveInt(val, 1000)
+		case "COUNT":
+			rule.Count, err = parsePositiveInt(val, 10000)
+		case "UNTIL":
+			rule.Until, err = parseRecurrenceDate(val)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(val, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(val, 1, 12)
+			for _, month := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "WKST":
+			weekday, ok := rruleWeekdays[val]
+			if !ok {
+				return nil, fmt.Errorf("invalid week start %q", val)
+			}
+			rule.WeekStart = weekday
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %s", key)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (r *RecurrenceRule) validate() error {
+	if r.Freq == "" {
+		return errors.New("recurrence rule requires FREQ")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return errors.New("recurrence rule can't have both COUNT and UNTIL")
+	}
+	if r.Freq == "WEEKLY" && len(r.ByMonthDay) > 0 {
+		return errors.New("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	}
+
+	for _, day := range r.ByDay {
+		if day.N == 0 {
+			continue
+		}
+		switch {
+		case r.Freq != "MONTHLY" && r.Freq != "YEARLY":
+			return errors.New("BYDAY ordinals are only allowed with FREQ=MONTHLY or FREQ=YEARLY")
+		case r.Freq == "MONTHLY" && (day.N < -5 || day.N > 5):
+			return errors.New("BYDAY ordinal must be between -5 and 5 for FREQ=MONTHLY")
+		case r.Freq == "YEARLY" && len(r.ByMonth) > 0 && (day.N < -5 || day.N > 5):
+			return errors.New("BYDAY ordinal must be between -5 and 5 when BYMONTH is set")
+		}
+	}
+
+	return nil
+}
+
+// Occurs reports whether the rule, started on dtstart, has an occurrence on date
+func (r *RecurrenceRule) Occurs(dtstart, date time.Time) bool {
+	dtstart = DateOnly(dtstart)
+	date = DateOnly(date)
+
+	if date.Before(dtstart) {
+		return false
+	}
+	if r.Until != nil && date.After(*r.Until) {
+		return false
+	}
+	if !r.matches(dtstart, date) {
+		return false
+	}
+	if r.Count > 0 {
+		return r.occurrencesBetween(dtstart, date) <= r.Count
+	}
+
+	return true
+}
+
+// occurrencesBetween counts the occurrences from dtstart up to and including date
+func (r *RecurrenceRule) occurrencesBetween(dtstart, date time.Time) int {
+	count := 0
+	for day := dtstart; !day.After(date); day = day.AddDate(0, 0, 1) {
+		if r.matches(dtstart, day) {
+			count++
+			if count > r.Count {
+				break
+			}
+		}
+	}
+	return count
+}
+
+// matches applies the interval and BYxxx parts to a single day, ignoring COUNT and UNTIL
+func (r *RecurrenceRule) matches(dtstart, date time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, date.Month()) {
+		return false
+	}
+
+	switch r.Freq {
+	case "DAILY":
+		if daysBetween(dtstart, date)%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, date) {
+			return false
+		}
+		return len(r.ByDay) == 0 || r.matchesWeekday(date, false)
+
+	case "WEEKLY":
+		weeks := daysBetween(startOfWeek(dtstart, r.WeekStart), startOfWeek(date, r.WeekStart)) / 7
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return date.Weekday() == dtstart.Weekday()
+		}
+		return r.matchesWeekday(date, false)
+
+	case "MONTHLY":
+		months := (date.Year()-dtstart.Year())*12 + int(date.Month()-dtstart.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			return date.Day() == dtstart.Day()
+		}
+		if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, date) {
+			return false
+		}
+		return len(r.ByDay) == 0 || r.matchesWeekday(date, false)
+
+	case "YEARLY":
+		if (date.Year()-dtstart.Year())%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			if len(r.ByMonth) == 0 && date.Month() != dtstart.Month() {
+				return false
+			}
+			return date.Day() == dtstart.Day()
+		}
+		if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, date) {
+			return false
+		}
+		// Ordinals count within the month when BYMONTH narrows the year, otherwise within the year
+		return len(r.ByDay) == 0 || r.matchesWeekday(date, len(r.ByMonth) == 0)
+	}
+
+	return false
+}
+
+func (r *RecurrenceRule) matchesWeekday(date time.Time, withinYear bool) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday != date.Weekday() {
+			continue
+		}
+		if day.N == 0 {
+			return true
+		}
+
+		var position, length int
+		if withinYear {
+			position = date.YearDay()
+			length = time.Date(date.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		} else {
+			position = date.Day()
+			length = daysIn(date.Year(), date.Month())
+		}
+
+		fromStart := (position-1)/7 + 1
+		fromEnd := -((length-position)/7 + 1)
+		if day.N == fromStart || day.N == fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(monthDays []int, date time.Time) bool {
+	length := daysIn(date.Year(), date.Month())
+	for _, day := range monthDays {
+		if day > 0 && day == date.Day() {
+			return true
+		}
+		if day < 0 && length+day+1 == date.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func startOfWeek(date time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(date.Weekday()) - int(weekStart) + 7) % 7
+	return date.AddDate(0, 0, -offset)
+}
+
+func parsePositiveInt(value string, maxValue int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > maxValue {
+		return 0, fmt.Errorf("must be a number between 1 and %d", maxValue)
+	}
+	return n, nil
+}
+
+func parseIntList(value string, minValue, maxValue int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < minValue || n > maxValue {
+			return nil, fmt.Errorf("%q must be a non-zero number between %d and %d", item, minValue, maxValue)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func parseByDay(value string) ([]RecurrenceWeekday, error) {
+	var days []RecurrenceWeekday
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		weekday, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		n := 0
+		if ordinal := item[:len(item)-2]; ordinal != "" {
+			var err error
+			n, err = strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid weekday ordinal %q", item)
+			}
+		}
+
+		days = append(days, RecurrenceWeekday{N: n, Weekday: weekday})
+	}
+	return days, nil
+}
+
+// parseRecurrenceDate accepts the DATE and UTC DATE-TIME forms of UNTIL
+func parseRecurrenceDate(value string) (*time.Time, error) {
+	for _, layout := range []string{"20060102", "20060102T150405Z", "20060102T150405"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			date := DateOnly(parsed)
+			return &date, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a valid date", value)
+}
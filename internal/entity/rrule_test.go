@@ -0,0 +1,177 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	until := day("2025-06-30")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    *RecurrenceRule
+		wantErr string
+	}{
+		{
+			name:  "daily",
+			value: "FREQ=DAILY",
+			want:  &RecurrenceRule{Freq: "DAILY", Interval: 1, WeekStart: time.Monday},
+		},
+		{
+			name:  "prefix, lowercase and spaces",
+			value: " RRULE:freq=weekly; interval=2 ;byday=mo,fr",
+			want: &RecurrenceRule{
+				Freq: "WEEKLY", Interval: 2, WeekStart: time.Monday,
+				ByDay: []RecurrenceWeekday{{Weekday: time.Monday}, {Weekday: time.Friday}},
+			},
+		},
+		{
+			name:  "monthly ordinals",
+			value: "FREQ=MONTHLY;BYDAY=1MO,-1FR;COUNT=6",
+			want: &RecurrenceRule{
+				Freq: "MONTHLY", Interval: 1, Count: 6, WeekStart: time.Monday,
+				ByDay: []RecurrenceWeekday{{N: 1, Weekday: time.Monday}, {N: -1, Weekday: time.Friday}},
+			},
+		},
+		{
+			name:  "month days until a date",
+			value: "FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20250630T235959Z",
+			want:  &RecurrenceRule{Freq: "MONTHLY", Interval: 1, ByMonthDay: []int{1, -1}, Until: &until, WeekStart: time.Monday},
+		},
+		{
+			name:  "yearly in months with a week start",
+			value: "FREQ=YEARLY;BYMONTH=3,9;BYDAY=2SU;WKST=SU",
+			want: &RecurrenceRule{
+				Freq: "YEARLY", Interval: 1, WeekStart: time.Sunday,
+				ByMonth: []time.Month{time.March, time.September},
+				ByDay:   []RecurrenceWeekday{{N: 2, Weekday: time.Sunday}},
+			},
+		},
+		{name: "empty", value: "RRULE:", wantErr: "empty"},
+		{name: "missing frequency", value: "INTERVAL=2", wantErr: "requires FREQ"},
+		{name: "unsupported frequency", value: "FREQ=HOURLY", wantErr: "unsupported recurrence frequency"},
+		{name: "unsupported part", value: "FREQ=DAILY;BYHOUR=9", wantErr: "unsupported recurrence rule part"},
+		{name: "part without value", value: "FREQ=DAILY;COUNT=", wantErr: "invalid recurrence rule part"},
+		{name: "repeated part", value: "FREQ=DAILY;FREQ=WEEKLY", wantErr: "repeated"},
+		{name: "zero interval", value: "FREQ=DAILY;INTERVAL=0", wantErr: "invalid INTERVAL"},
+		{name: "count and until", value: "FREQ=DAILY;COUNT=3;UNTIL=20250630", wantErr: "both COUNT and UNTIL"},
+		{name: "invalid until", value: "FREQ=DAILY;UNTIL=2025-06-30", wantErr: "invalid UNTIL"},
+		{name: "invalid weekday", value: "FREQ=WEEKLY;BYDAY=XX", wantErr: "invalid BYDAY"},
+		{name: "ordinal of a weekly rule", value: "FREQ=WEEKLY;BYDAY=1MO", wantErr: "ordinals are only allowed"},
+		{name: "ordinal beyond the month", value: "FREQ=MONTHLY;BYDAY=6MO", wantErr: "between -5 and 5"},
+		{name: "month day of a weekly rule", value: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: "not allowed with FREQ=WEEKLY"},
+		{name: "zero month day", value: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: "invalid BYMONTHDAY"},
+		{name: "invalid month", value: "FREQ=YEARLY;BYMONTH=13", wantErr: "invalid BYMONTH"},
+		{name: "invalid week start", value: "FREQ=WEEKLY;WKST=XX", wantErr: "invalid week start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, rule)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule)
+		})
+	}
+}
+
+func TestRecurrenceRuleOccurs(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart string
+		dates   []string
+		want    []bool
+	}{
+		{
+			name:    "every third day",
+			rule:    "FREQ=DAILY;INTERVAL=3",
+			dtstart: "2025-03-01",
+			dates:   []string{"2025-02-26", "2025-03-01", "2025-03-02", "2025-03-04", "2025-03-31"},
+			want:    []bool{false, true, false, true, true},
+		},
+		{
+			name:    "every other week on weekdays",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			dtstart: "2025-03-03",
+			dates:   []string{"2025-03-03", "2025-03-05", "2025-03-10", "2025-03-17", "2025-03-18"},
+			want:    []bool{true, true, false, true, false},
+		},
+		{
+			name:    "weekly on the weekday of the start",
+			rule:    "FREQ=WEEKLY",
+			dtstart: "2025-03-01",
+			dates:   []string{"2025-03-08", "2025-03-09"},
+			want:    []bool{true, false},
+		},
+		{
+			name:    "first Monday and last Friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=1MO,-1FR",
+			dtstart: "2025-03-01",
+			dates:   []string{"2025-03-03", "2025-03-10", "2025-03-28", "2025-03-21", "2025-04-07", "2025-04-25"},
+			want:    []bool{true, false, true, false, true, true},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: "2025-01-15",
+			dates:   []string{"2025-01-31", "2025-02-28", "2025-02-27", "2024-02-29"},
+			want:    []bool{true, true, false, false},
+		},
+		{
+			name:    "monthly on the day of the start",
+			rule:    "FREQ=MONTHLY",
+			dtstart: "2025-01-31",
+			dates:   []string{"2025-03-31", "2025-02-28"},
+			want:    []bool{true, false},
+		},
+		{
+			name:    "yearly on the start date",
+			rule:    "FREQ=YEARLY",
+			dtstart: "2024-07-04",
+			dates:   []string{"2025-07-04", "2025-07-05", "2026-07-04"},
+			want:    []bool{true, false, true},
+		},
+		{
+			name:    "second Sunday of May",
+			rule:    "FREQ=YEARLY;BYMONTH=5;BYDAY=2SU",
+			dtstart: "2025-01-01",
+			dates:   []string{"2025-05-11", "2025-05-04", "2026-05-10"},
+			want:    []bool{true, false, true},
+		},
+		{
+			name:    "count limits the occurrences",
+			rule:    "FREQ=WEEKLY;BYDAY=TU;COUNT=2",
+			dtstart: "2025-03-01",
+			dates:   []string{"2025-03-04", "2025-03-11", "2025-03-18"},
+			want:    []bool{true, true, false},
+		},
+		{
+			name:    "until is inclusive",
+			rule:    "FREQ=DAILY;UNTIL=20250305",
+			dtstart: "2025-03-01",
+			dates:   []string{"2025-03-05", "2025-03-06"},
+			want:    []bool{true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			require.NoError(t, err)
+
+			for i, date := range tt.dates {
+				assert.Equal(t, tt.want[i], rule.Occurs(day(tt.dtstart), day(date)), date)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"reflect"
+	"time"
+)
+
+// ScheduleRevision records the schedule a habit followed from EffectiveFrom until the
+// next revision. Keeping the old schedules lets history be judged by the rules that
+// applied at the time instead of the habit's current ones.
+type ScheduleRevision struct {
+	ID            string    `json:"id"`
+	HabitID       string    `json:"habit_id"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	Schedule
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviseSchedule records the habit's current schedule as in force from effectiveFrom
+// and returns the revision to store. A revision made on the same day as the latest one
+// replaces it.
+func (h *Habit) ReviseSchedule(id string, effectiveFrom time.Time) *ScheduleRevision {
+	revision := &ScheduleRevision{
+		ID:            id,
+		HabitID:       h.ID,
+		EffectiveFrom: DateOnly(effectiveFrom),
+		Schedule:      h.Schedule,
+		CreatedAt:     time.Now(),
+	}
+
+	if last := len(h.ScheduleHistory) - 1; last >= 0 && !h.ScheduleHistory[last].EffectiveFrom.Before(revision.EffectiveFrom) {
+		h.ScheduleHistory[last] = revision
+	} else {
+		h.ScheduleHistory = append(h.ScheduleHistory, revision)
+	}
+
+	return revision
+}
+
+// ScheduleChanged reports whether the habit's schedule differs from before
+func (h *Habit) ScheduleChanged(before Schedule) bool {
+	return !reflect.DeepEqual(before, h.Schedule)
+}
+
+// ScheduleOn returns the schedule that was in force on date. Dates before the first
+// revision, such as backfilled ones, follow the habit's original schedule, and dates
+// from the latest revision on follow its current one.
+func (h *Habit) ScheduleOn(date time.Time) Schedule {
+	date = DateOnly(date)
+
+	for i := len(h.ScheduleHistory) - 2; i >= 0; i-- {
+		if date.Before(h.ScheduleHistory[i+1].EffectiveFrom) && (i == 0 || !date.Before(h.ScheduleHistory[i].EffectiveFrom)) {
+			return h.ScheduleHistory[i].Schedule
+		}
+	}
+
+	return h.Schedule
+}
+
+// scheduleSpan is a stretch of days, From to To inclusive, that one schedule covers
+type scheduleSpan struct {
+	Schedule
+	From time.Time
+	To   time.Time
+	// First and last day the schedule was in force, zero when unbounded
+	start time.Time
+	end   time.Time
+	// The parsed Recurrence of custom schedules
+	rule *RecurrenceRule
+}
+
+// scheduleSpans splits from to to, inclusive, into the stretches covered by each of the
+// habit's schedules, in order
+func (h *Habit) scheduleSpans(from, to time.Time) []scheduleSpan {
+	if len(h.ScheduleHistory) < 2 {
+		return []scheduleSpan{{Schedule: h.Schedule, From: from, To: to, rule: h.recurrenceRule(h.Schedule)}}
+	}
+
+	var spans []scheduleSpan
+	last := len(h.ScheduleHistory) - 1
+	for i, revision := range h.ScheduleHistory {
+		span := scheduleSpan{Schedule: revision.Schedule, From: from, To: to}
+		if i > 0 {
+			span.start = revision.EffectiveFrom
+			span.From = later(from, span.start)
+		}
+		if i < last {
+			span.end = h.ScheduleHistory[i+1].EffectiveFrom.AddDate(0, 0, -1)
+			span.To = earlier(to, span.end)
+		} else {
+			span.Schedule = h.Schedule
+		}
+		if !span.From.After(span.To) {
+			span.rule = h.recurrenceRule(span.Schedule)
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+// period returns the quota period of the span containing date, cut short where the
+// schedule started or ended within it
+func (s scheduleSpan) period(date time.Time) (start, end time.Time) {
+	start = s.PeriodStart(date)
+	end = s.nextPeriodStart(start).AddDate(0, 0, -1)
+	if !s.start.IsZero() {
+		start = later(start, s.start)
+	}
+	if !s.end.IsZero() {
+		end = earlier(end, s.end)
+	}
+	return start, end
+}
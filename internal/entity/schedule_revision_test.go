@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleOn(t *testing.T) {
+	daily := Schedule{Frequency: "daily"}
+	weekly := Schedule{Frequency: "weekly"}
+	quota := Schedule{Frequency: "times_per_week", PeriodTarget: intPtr(3)}
+
+	tests := []struct {
+		name    string
+		history []*ScheduleRevision
+		date    string
+		want    Schedule
+	}{
+		{
+			name: "habit without revisions",
+			date: "2025-03-05",
+			want: quota,
+		},
+		{
+			name:    "habit with a single revision",
+			history: []*ScheduleRevision{{EffectiveFrom: day("2025-03-01"), Schedule: quota}},
+			date:    "2025-02-20",
+			want:    quota,
+		},
+		{
+			name: "backfilled date before the first revision",
+			history: []*ScheduleRevision{
+				{EffectiveFrom: day("2025-03-01"), Schedule: daily},
+				{EffectiveFrom: day("2025-03-10"), Schedule: weekly},
+				{EffectiveFrom: day("2025-03-20"), Schedule: quota},
+			},
+			date: "2025-02-20",
+			want: daily,
+		},
+		{
+			name: "first day of a revision",
+			history: []*ScheduleRevision{
+				{EffectiveFrom: day("2025-03-01"), Schedule: daily},
+				{EffectiveFrom: day("2025-03-10"), Schedule: weekly},
+				{EffectiveFrom: day("2025-03-20"), Schedule: quota},
+			},
+			date: "2025-03-10",
+			want: weekly,
+		},
+		{
+			name: "last day of a revision",
+			history: []*ScheduleRevision{
+				{EffectiveFrom: day("2025-03-01"), Schedule: daily},
+				{EffectiveFrom: day("2025-03-10"), Schedule: weekly},
+				{EffectiveFrom: day("2025-03-20"), Schedule: quota},
+			},
+			date: "2025-03-09",
+			want: daily,
+		},
+		{
+			name: "date from the latest revision on",
+			history: []*ScheduleRevision{
+				{EffectiveFrom: day("2025-03-01"), Schedule: daily},
+				{EffectiveFrom: day("2025-03-10"), Schedule: weekly},
+				{EffectiveFrom: day("2025-03-20"), Schedule: quota},
+			},
+			date: "2025-04-01",
+			want: quota,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			habit := testHabit("count", quota)
+			habit.ScheduleHistory = tt.history
+
+			assert.Equal(t, tt.want, habit.ScheduleOn(day(tt.date).Add(15*time.Hour)))
+		})
+	}
+}
+
+func TestReviseSchedule(t *testing.T) {
+	habit := testHabit("count", Schedule{Frequency: "daily"})
+
+	habit.ReviseSchedule("revision-1", day("2025-03-01").Add(9*time.Hour))
+	habit.Schedule = Schedule{Frequency: "weekly"}
+	habit.ReviseSchedule("revision-2", day("2025-03-10"))
+	habit.Schedule = Schedule{Frequency: "monthly"}
+	habit.ReviseSchedule("revision-3", day("2025-03-10").Add(18*time.Hour))
+
+	if assert.Len(t, habit.ScheduleHistory, 2) {
+		assert.Equal(t, day("2025-03-01"), habit.ScheduleHistory[0].EffectiveFrom)
+		assert.Equal(t, "daily", habit.ScheduleHistory[0].Frequency)
+		assert.Equal(t, "revision-3", habit.ScheduleHistory[1].ID)
+		assert.Equal(t, day("2025-03-10"), habit.ScheduleHistory[1].EffectiveFrom)
+		assert.Equal(t, "monthly", habit.ScheduleHistory[1].Frequency)
+	}
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// maxUserAgentLength caps the user agent kept for a session
+const maxUserAgentLength = 512
+
+// Session is a login on one device. Its refresh tokens form a single family, so
+// revoking the session revokes all of them and the access tokens issued with them.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	UserAgent  *string    `json:"user_agent"`
+	IPAddress  *string    `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// NewSession starts a session that lasts as long as its first refresh token
+func NewSession(id, userID, userAgent, ipAddress string, now time.Time) *Session {
+	session := &Session{
+		ID:         id,
+		UserID:     userID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+	}
+	session.setClient(userAgent, ipAddress)
+	return session
+}
+
+// Touch records a use of the session from the given client, which keeps it alive for
+// another RefreshTokenTTL
+func (s *Session) Touch(userAgent, ipAddress string, now time.Time) {
+	s.setClient(userAgent, ipAddress)
+	s.LastUsedAt = now
+	s.ExpiresAt = now.Add(RefreshTokenTTL)
+}
+
+// IsActive reports whether the session can still be used at now
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+func (s *Session) setClient(userAgent, ipAddress string) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+	if userAgent != "" {
+		s.UserAgent = &userAgent
+	}
+	if ipAddress != "" {
+		s.IPAddress = &ipAddress
+	}
+}
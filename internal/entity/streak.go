@@ -0,0 +1,286 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// DateOnly strips the clock from t and returns midnight UTC of the same calendar day
+func DateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of calendar days from one date to a later one
+func daysBetween(from, to time.Time) int {
+	return int(DateOnly(to).Sub(DateOnly(from)).Hours() / 24)
+}
+
+// IsScheduledOn reports whether the habit is expected to be performed on the given date,
+// according to the schedule that was in force on that date
+func (h *Habit) IsScheduledOn(date time.Time) bool {
+	schedule := h.ScheduleOn(date)
+	return schedule.occursOn(date, h.CreatedAt, h.recurrenceRule(schedule))
+}
+
+// recurrenceRule returns the parsed RRULE of a custom schedule of the habit, or nil for
+// other schedules and invalid rules. Rules are parsed once and kept on the habit, so
+// walking its history doesn't parse them again for every day.
+func (h *Habit) recurrenceRule(s Schedule) *RecurrenceRule {
+	if s.Frequency != "custom" || s.Recurrence == nil {
+		return nil
+	}
+
+	if rule, ok := h.recurrenceRules[*s.Recurrence]; ok {
+		return rule
+	}
+
+	rule, err := ParseRecurrenceRule(*s.Recurrence)
+	if err != nil {
+		rule = nil
+	}
+	if h.recurrenceRules == nil {
+		h.recurrenceRules = make(map[string]*RecurrenceRule)
+	}
+	h.recurrenceRules[*s.Recurrence] = rule
+
+	return rule
+}
+
+// occursOn reports whether the schedule expects the habit on date. Weekly and monthly
+// schedules without target days repeat on the day of createdAt, and custom schedules
+// follow rule, the schedule's parsed Recurrence.
+func (s Schedule) occursOn(date, createdAt time.Time, rule *RecurrenceRule) bool {
+	date = DateOnly(date)
+
+	switch s.Frequency {
+	case "daily":
+		return true
+	case "times_per_week", "times_per_month":
+		// Any day of the period can count toward the quota
+		return true
+	case "interval":
+		if s.IntervalDays == nil || s.AnchorDate == nil {
+			return false
+		}
+		anchor := DateOnly(*s.AnchorDate)
+		if date.Before(anchor) {
+			return false
+		}
+		return daysBetween(anchor, date)%*s.IntervalDays == 0
+	case "custom":
+		if rule == nil || s.AnchorDate == nil {
+			return false
+		}
+		return rule.Occurs(*s.AnchorDate, date)
+	case "weekly":
+		// Without explicit target days a weekly habit repeats on the weekday it was created
+		if s.TargetDays == nil || len(s.TargetDays.Days) == 0 {
+			return date.Weekday() == createdAt.Weekday()
+		}
+		weekday := strings.ToLower(date.Weekday().String())
+		for _, day := range s.TargetDays.Days {
+			if dayStr, ok := day.(string); ok && dayStr == weekday {
+				return true
+			}
+		}
+		return false
+	case "monthly":
+		// Without explicit target days a monthly habit repeats on the day of month it was created
+		if s.TargetDays == nil || len(s.TargetDays.Days) == 0 {
+			daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+			return date.Day() == min(createdAt.Day(), daysInMonth)
+		}
+		for _, day := range s.TargetDays.GetValidMonthlyDays(date.Year(), date.Month()) {
+			if day == date.Day() {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// CalculateStreaks walks every scheduled day from the first fulfilled completion up to
+// today and returns the current and best streak. A scheduled day without a fulfilled
+// completion breaks the streak, except today, which is still in progress, and days
+// covered by a streak freeze token. Partial completions and completions logged on days
+// that are not scheduled are ignored.
+//
+// Under a period quota the streak counts consecutive weeks or months that met the quota
+// instead, and quit habits count the days since their last slip. Each day is judged by
+// the schedule in force on it, and days off in timeOff are treated like days that are
+// not scheduled.
+func (h *Habit) CalculateStreaks(completions []*HabitCompletion, timeOff *TimeOff, today time.Time) (current, best int) {
+	today = DateOnly(today)
+
+	if h.IsQuit() {
+		return h.calculateCleanStreaks(completions, timeOff, today)
+	}
+
+	current, best, _ = h.calculateScheduledStreaks(completions, timeOff, today)
+	return current, best
+}
+
+// calculateScheduledStreaks walks the habit's history one schedule at a time, so a
+// streak carries over when the schedule changes. Scheduled days are the steps of a streak
+// under day based schedules, and quota periods under quota schedules. A period only needs
+// its days that aren't off, and one that is entirely off is skipped; the period in
+// progress doesn't break the streak.
+//
+// It also keeps the ledger of streak freeze tokens: every FreezeEarnDays fulfilled days
+// of a streak earn a token, and a missed day of a running streak spends one instead of
+// breaking it. A frozen day keeps the streak without extending it. Missed quota periods
+// can't be frozen.
+func (h *Habit) calculateScheduledStreaks(completions []*HabitCompletion, timeOff *TimeOff, today time.Time) (current, best int, freezes *FreezeLedger) {
+	freezes = &FreezeLedger{EarnDays: FreezeEarnDays, Events: []FreezeEvent{}}
+
+	completed := make(map[time.Time]bool, len(completions))
+	var first time.Time
+	for _, completion := range completions {
+		day := DateOnly(completion.CompletionDate)
+		if day.After(today) || !completion.IsFulfilled() || timeOff.Covers(h.ID, day) {
+			continue
+		}
+		completed[day] = true
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+	}
+
+	if first.IsZero() {
+		return 0, 0, freezes
+	}
+
+	// Fulfilled days since the last token was earned or the streak broke
+	progress := 0
+	for _, span := range h.scheduleSpans(h.ScheduleOn(first).PeriodStart(first), today) {
+		if !IsPeriodQuota(span.Frequency) {
+			for day := span.From; !day.After(span.To); day = day.AddDate(0, 0, 1) {
+				if !span.occursOn(day, h.CreatedAt, span.rule) || timeOff.Covers(h.ID, day) {
+					continue
+				}
+				if completed[day] {
+					current++
+					best = max(best, current)
+					progress++
+					if progress == FreezeEarnDays {
+						progress = 0
+						freezes.add(day, FreezeEarned, current)
+					}
+					continue
+				}
+				if day.Equal(today) {
+					continue
+				}
+				if current > 0 && freezes.Balance > 0 {
+					freezes.add(day, FreezeUsed, current)
+					continue
+				}
+				current = 0
+				progress = 0
+			}
+			continue
+		}
+
+		if span.PeriodTarget == nil {
+			continue
+		}
+
+		for day := span.From; !day.After(span.To); {
+			start, end := span.period(day)
+			day = end.AddDate(0, 0, 1)
+
+			quota := min(*span.PeriodTarget, h.availableDays(start, end, timeOff))
+			if quota == 0 {
+				continue
+			}
+
+			got := 0
+			for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+				if completed[d] {
+					got++
+				}
+			}
+
+			if got >= quota {
+				current++
+				best = max(best, current)
+				continue
+			}
+			if !end.Before(today) {
+				continue
+			}
+			current = 0
+			progress = 0
+		}
+	}
+
+	return current, best, freezes
+}
+
+// Freezes returns the habit's streak freeze ledger as of today. Only the days of day
+// based schedules earn and spend tokens, so the ledger of quit habits is always empty.
+func (h *Habit) Freezes(completions []*HabitCompletion, timeOff *TimeOff, today time.Time) *FreezeLedger {
+	if h.IsQuit() {
+		return &FreezeLedger{EarnDays: FreezeEarnDays, Events: []FreezeEvent{}}
+	}
+
+	_, _, freezes := h.calculateScheduledStreaks(completions, timeOff, DateOnly(today))
+	return freezes
+}
+
+// calculateCleanStreaks measures the streaks of quit habits, where every entry is a slip.
+// The current streak is the number of days since the last slip, or since tracking started
+// when there was none, and the best streak is the longest such run. Days off neither
+// extend the run nor end it, even with a slip logged.
+func (h *Habit) calculateCleanStreaks(completions []*HabitCompletion, timeOff *TimeOff, today time.Time) (current, best int) {
+	slips := h.markedDays(completions)
+
+	for day := h.trackingStart(completions).AddDate(0, 0, 1); !day.After(today); day = day.AddDate(0, 0, 1) {
+		if timeOff.Covers(h.ID, day) {
+			continue
+		}
+		if slips[day] {
+			best = max(best, current)
+			current = 0
+			continue
+		}
+		current++
+	}
+
+	return current, max(best, current)
+}
+
+// PeriodStart returns the first day of the quota period containing date. Weeks start on Monday.
+func (s Schedule) PeriodStart(date time.Time) time.Time {
+	date = DateOnly(date)
+
+	switch s.Frequency {
+	case "times_per_week":
+		offset := (int(date.Weekday()) + 6) % 7
+		return date.AddDate(0, 0, -offset)
+	case "times_per_month":
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return date
+	}
+}
+
+func (s Schedule) nextPeriodStart(periodStart time.Time) time.Time {
+	switch s.Frequency {
+	case "times_per_week":
+		return periodStart.AddDate(0, 0, 7)
+	case "times_per_month":
+		return periodStart.AddDate(0, 1, 0)
+	default:
+		return periodStart.AddDate(0, 0, 1)
+	}
+}
+
+// RecalculateStats rebuilds CurrentStreak, BestStreak and TotalCompletions from the
+// habit's full completion history and days off
+func (h *Habit) RecalculateStats(completions []*HabitCompletion, timeOff *TimeOff, today time.Time) {
+	h.CurrentStreak, h.BestStreak = h.CalculateStreaks(completions, timeOff, today)
+	h.TotalCompletions = len(completions)
+}
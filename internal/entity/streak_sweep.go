@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// StreakReset records a streak that was broken by the nightly sweep of a user's habits
+type StreakReset struct {
+	UserID         string    `json:"user_id"`
+	SweepDate      time.Time `json:"sweep_date"`
+	HabitID        string    `json:"habit_id"`
+	PreviousStreak int       `json:"previous_streak"`
+	ResetAt        time.Time `json:"reset_at"`
+}
@@ -0,0 +1,279 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHabitID = "habit-1"
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func day(value string) time.Time {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+// completed returns a fulfilled completion of the test habit on each of days
+func completed(days ...string) []*HabitCompletion {
+	completions := make([]*HabitCompletion, 0, len(days))
+	for _, d := range days {
+		completions = append(completions, &HabitCompletion{HabitID: testHabitID, CompletionDate: day(d), Count: 1, TargetCount: 1})
+	}
+	return completions
+}
+
+// testHabit returns a habit of the kind and schedule created on 2025-03-01, a Saturday
+func testHabit(kind string, schedule Schedule) *Habit {
+	return &Habit{ID: testHabitID, Kind: kind, Schedule: schedule, CreatedAt: day("2025-03-01")}
+}
+
+func TestCalculateStreaks(t *testing.T) {
+	anchor := day("2025-03-01")
+	mondaysAndFridays := &TargetDays{Days: []any{"monday", "friday"}}
+
+	revisedToMondays := testHabit("count", Schedule{Frequency: "weekly", TargetDays: &TargetDays{Days: []any{"monday"}}})
+	revisedToMondays.ScheduleHistory = []*ScheduleRevision{
+		{EffectiveFrom: day("2025-03-01"), Schedule: Schedule{Frequency: "daily"}},
+		{EffectiveFrom: day("2025-03-10"), Schedule: revisedToMondays.Schedule},
+	}
+
+	tests := []struct {
+		name        string
+		habit       *Habit
+		completions []*HabitCompletion
+		timeOff     *TimeOff
+		today       string
+		wantCurrent int
+		wantBest    int
+	}{
+		{
+			name:  "no completions",
+			habit: testHabit("count", Schedule{Frequency: "daily"}),
+			today: "2025-03-14",
+		},
+		{
+			name:        "daily streak with today still open",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-10", "2025-03-11", "2025-03-12", "2025-03-13"),
+			today:       "2025-03-14",
+			wantCurrent: 4,
+			wantBest:    4,
+		},
+		{
+			name:        "daily streak including today",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-12", "2025-03-13", "2025-03-14"),
+			today:       "2025-03-14",
+			wantCurrent: 3,
+			wantBest:    3,
+		},
+		{
+			name:        "missed day breaks the streak",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-02", "2025-03-03", "2025-03-04", "2025-03-05", "2025-03-06", "2025-03-07", "2025-03-09", "2025-03-10"),
+			today:       "2025-03-11",
+			wantCurrent: 2,
+			wantBest:    6,
+		},
+		{
+			name:        "completions in the future are ignored",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-13", "2025-03-15", "2025-03-16"),
+			today:       "2025-03-14",
+			wantCurrent: 1,
+			wantBest:    1,
+		},
+		{
+			name:  "partial completion doesn't count",
+			habit: testHabit("count", Schedule{Frequency: "daily"}),
+			completions: []*HabitCompletion{
+				{HabitID: testHabitID, CompletionDate: day("2025-03-12"), Count: 2, TargetCount: 2},
+				{HabitID: testHabitID, CompletionDate: day("2025-03-13"), Count: 1, TargetCount: 2},
+			},
+			today:    "2025-03-14",
+			wantBest: 1,
+		},
+		{
+			name:        "freeze earned by a week of days covers a missed day",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-01", "2025-03-02", "2025-03-03", "2025-03-04", "2025-03-05", "2025-03-06", "2025-03-07", "2025-03-09", "2025-03-10"),
+			today:       "2025-03-11",
+			wantCurrent: 9,
+			wantBest:    9,
+		},
+		{
+			name:        "spent freeze doesn't cover a second missed day",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-01", "2025-03-02", "2025-03-03", "2025-03-04", "2025-03-05", "2025-03-06", "2025-03-07", "2025-03-09", "2025-03-11"),
+			today:       "2025-03-12",
+			wantCurrent: 1,
+			wantBest:    8,
+		},
+		{
+			name:        "weekly target days",
+			habit:       testHabit("count", Schedule{Frequency: "weekly", TargetDays: mondaysAndFridays}),
+			completions: completed("2025-03-03", "2025-03-04", "2025-03-07", "2025-03-10"),
+			today:       "2025-03-13",
+			wantCurrent: 3,
+			wantBest:    3,
+		},
+		{
+			name:        "missed weekly target day",
+			habit:       testHabit("count", Schedule{Frequency: "weekly", TargetDays: mondaysAndFridays}),
+			completions: completed("2025-03-03", "2025-03-10", "2025-03-14"),
+			today:       "2025-03-14",
+			wantCurrent: 2,
+			wantBest:    2,
+		},
+		{
+			name:        "weekly without target days repeats on the weekday of creation",
+			habit:       testHabit("count", Schedule{Frequency: "weekly"}),
+			completions: completed("2025-03-01", "2025-03-08", "2025-03-15"),
+			today:       "2025-03-20",
+			wantCurrent: 3,
+			wantBest:    3,
+		},
+		{
+			name:        "monthly target days",
+			habit:       testHabit("count", Schedule{Frequency: "monthly", TargetDays: &TargetDays{Days: []any{float64(1), "last"}}}),
+			completions: completed("2025-01-31", "2025-02-01", "2025-02-28", "2025-03-01"),
+			today:       "2025-03-14",
+			wantCurrent: 4,
+			wantBest:    4,
+		},
+		{
+			name:        "every other day",
+			habit:       testHabit("count", Schedule{Frequency: "interval", IntervalDays: intPtr(2), AnchorDate: &anchor}),
+			completions: completed("2025-03-09", "2025-03-11", "2025-03-13"),
+			today:       "2025-03-14",
+			wantCurrent: 3,
+			wantBest:    3,
+		},
+		{
+			name:        "missed interval day",
+			habit:       testHabit("count", Schedule{Frequency: "interval", IntervalDays: intPtr(2), AnchorDate: &anchor}),
+			completions: completed("2025-03-07", "2025-03-09", "2025-03-13"),
+			today:       "2025-03-14",
+			wantCurrent: 1,
+			wantBest:    2,
+		},
+		{
+			name:        "custom recurrence",
+			habit:       testHabit("count", Schedule{Frequency: "custom", Recurrence: strPtr("FREQ=WEEKLY;BYDAY=MO,WE"), AnchorDate: &anchor}),
+			completions: completed("2025-03-03", "2025-03-05", "2025-03-10", "2025-03-12"),
+			today:       "2025-03-13",
+			wantCurrent: 4,
+			wantBest:    4,
+		},
+		{
+			name:        "weekly quota met with the current week in progress",
+			habit:       testHabit("count", Schedule{Frequency: "times_per_week", PeriodTarget: intPtr(2)}),
+			completions: completed("2025-03-03", "2025-03-05", "2025-03-11", "2025-03-12"),
+			today:       "2025-03-18",
+			wantCurrent: 2,
+			wantBest:    2,
+		},
+		{
+			name:        "missed weekly quota",
+			habit:       testHabit("count", Schedule{Frequency: "times_per_week", PeriodTarget: intPtr(2)}),
+			completions: completed("2025-02-25", "2025-02-26", "2025-03-04", "2025-03-10", "2025-03-11"),
+			today:       "2025-03-12",
+			wantCurrent: 1,
+			wantBest:    1,
+		},
+		{
+			name:        "monthly quota",
+			habit:       testHabit("count", Schedule{Frequency: "times_per_month", PeriodTarget: intPtr(3)}),
+			completions: completed("2025-01-02", "2025-01-20", "2025-01-31", "2025-02-03", "2025-02-04", "2025-02-05", "2025-03-01"),
+			today:       "2025-03-14",
+			wantCurrent: 2,
+			wantBest:    2,
+		},
+		{
+			name:        "skipped day doesn't break the streak",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-10", "2025-03-11", "2025-03-13"),
+			timeOff:     NewTimeOff([]*HabitSkip{{HabitID: testHabitID, SkipDate: day("2025-03-12")}}, nil),
+			today:       "2025-03-14",
+			wantCurrent: 3,
+			wantBest:    3,
+		},
+		{
+			name:        "skip of another habit doesn't protect the streak",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-10", "2025-03-11", "2025-03-13"),
+			timeOff:     NewTimeOff([]*HabitSkip{{HabitID: "habit-2", SkipDate: day("2025-03-12")}}, nil),
+			today:       "2025-03-14",
+			wantCurrent: 1,
+			wantBest:    2,
+		},
+		{
+			name:        "pause doesn't break the streak",
+			habit:       testHabit("count", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-05", "2025-03-06", "2025-03-10"),
+			timeOff:     NewTimeOff(nil, []*Pause{{StartDate: day("2025-03-07"), EndDate: day("2025-03-09")}}),
+			today:       "2025-03-11",
+			wantCurrent: 3,
+			wantBest:    3,
+		},
+		{
+			name:        "streak carries over a schedule revision",
+			habit:       revisedToMondays,
+			completions: completed("2025-03-07", "2025-03-08", "2025-03-09", "2025-03-10", "2025-03-12"),
+			today:       "2025-03-16",
+			wantCurrent: 4,
+			wantBest:    4,
+		},
+		{
+			name:        "earlier days are judged by the earlier schedule",
+			habit:       revisedToMondays,
+			completions: completed("2025-03-06", "2025-03-08", "2025-03-10"),
+			today:       "2025-03-11",
+			wantCurrent: 1,
+			wantBest:    1,
+		},
+		{
+			name:        "days since the last slip",
+			habit:       testHabit("quit", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-05"),
+			today:       "2025-03-14",
+			wantCurrent: 9,
+			wantBest:    9,
+		},
+		{
+			name:        "slip days don't count towards the best streak",
+			habit:       testHabit("quit", Schedule{Frequency: "daily"}),
+			completions: completed("2025-03-08", "2025-03-11"),
+			today:       "2025-03-14",
+			wantCurrent: 3,
+			wantBest:    6,
+		},
+		{
+			name:        "no slips since tracking started",
+			habit:       testHabit("quit", Schedule{Frequency: "daily"}),
+			today:       "2025-03-14",
+			wantCurrent: 13,
+			wantBest:    13,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, best := tt.habit.CalculateStreaks(tt.completions, tt.timeOff, day(tt.today))
+			assert.Equal(t, tt.wantCurrent, current, "current streak")
+			assert.Equal(t, tt.wantBest, best, "best streak")
+		})
+	}
+}
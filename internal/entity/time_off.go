@@ -0,0 +1,164 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxPauseDays is the longest a single pause can last
+const MaxPauseDays = 366
+
+// HabitSkip excuses a habit on a single day, e.g. when the user is sick
+type HabitSkip struct {
+	ID        string    `json:"id"`
+	HabitID   string    `json:"habit_id"`
+	UserID    string    `json:"user_id"`
+	SkipDate  time.Time `json:"skip_date"`
+	Reason    *string   `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewHabitSkip creates a new skip with validation
+func NewHabitSkip(id, habitID, userID string, skipDate time.Time, reason *string) (*HabitSkip, error) {
+	skip := &HabitSkip{
+		ID:        id,
+		HabitID:   habitID,
+		UserID:    userID,
+		SkipDate:  DateOnly(skipDate),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	if skip.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	if skip.HabitID == "" {
+		return nil, errors.New("habit ID is required")
+	}
+	if skip.UserID == "" {
+		return nil, errors.New("user ID is required")
+	}
+	if skipDate.IsZero() {
+		return nil, errors.New("skip date is required")
+	}
+
+	return skip, nil
+}
+
+// Pause excuses all of a user's habits from StartDate to EndDate, inclusive, e.g. during
+// a vacation
+type Pause struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Reason    *string   `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewPause creates a new pause with validation
+func NewPause(id, userID string, startDate, endDate time.Time, reason *string) (*Pause, error) {
+	pause := &Pause{
+		ID:        id,
+		UserID:    userID,
+		StartDate: DateOnly(startDate),
+		EndDate:   DateOnly(endDate),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	if pause.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	if pause.UserID == "" {
+		return nil, errors.New("user ID is required")
+	}
+	if startDate.IsZero() || endDate.IsZero() {
+		return nil, errors.New("start and end date are required")
+	}
+	if pause.EndDate.Before(pause.StartDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	if pause.Days() > MaxPauseDays {
+		return nil, errors.New("pause is too long")
+	}
+
+	return pause, nil
+}
+
+// Days returns the number of days the pause covers
+func (p *Pause) Days() int {
+	return daysBetween(p.StartDate, p.EndDate) + 1
+}
+
+// Covers reports whether day falls within the pause
+func (p *Pause) Covers(day time.Time) bool {
+	day = DateOnly(day)
+	return !day.Before(p.StartDate) && !day.After(p.EndDate)
+}
+
+// TimeOff holds a user's skips and pauses. Days off are neutral: a habit is neither
+// expected nor missed on them, so they don't count toward streaks or completion rates.
+// A nil TimeOff has no days off.
+type TimeOff struct {
+	Skips  []*HabitSkip
+	Pauses []*Pause
+
+	skipped map[string]map[time.Time]bool
+}
+
+// NewTimeOff indexes skips and pauses for lookups by habit and day
+func NewTimeOff(skips []*HabitSkip, pauses []*Pause) *TimeOff {
+	timeOff := &TimeOff{
+		Skips:   skips,
+		Pauses:  pauses,
+		skipped: make(map[string]map[time.Time]bool),
+	}
+
+	for _, skip := range skips {
+		days, ok := timeOff.skipped[skip.HabitID]
+		if !ok {
+			days = make(map[time.Time]bool)
+			timeOff.skipped[skip.HabitID] = days
+		}
+		days[DateOnly(skip.SkipDate)] = true
+	}
+
+	return timeOff
+}
+
+// Covers reports whether the habit is excused on day by a skip or a pause
+func (t *TimeOff) Covers(habitID string, day time.Time) bool {
+	if t == nil {
+		return false
+	}
+
+	day = DateOnly(day)
+	if t.skipped[habitID][day] {
+		return true
+	}
+
+	for _, pause := range t.Pauses {
+		if pause.Covers(day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expectedOn reports whether the habit is scheduled on day and not excused by timeOff
+func (h *Habit) expectedOn(day time.Time, timeOff *TimeOff) bool {
+	return h.IsScheduledOn(day) && !timeOff.Covers(h.ID, day)
+}
+
+// availableDays counts the days between from and to, inclusive, that aren't excused by timeOff
+func (h *Habit) availableDays(from, to time.Time, timeOff *TimeOff) int {
+	days := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !timeOff.Covers(h.ID, day) {
+			days++
+		}
+	}
+	return days
+}
@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTimerNotRunning = errors.New("timer is not running")
+	ErrTimerStopped    = errors.New("timer is already stopped")
+)
+
+// TimerSession times a duration habit. A session runs in one or more segments separated
+// by pauses; ElapsedSeconds holds the time of the finished segments and ResumedAt marks
+// the start of the running one.
+type TimerSession struct {
+	ID             string     `json:"id"`
+	HabitID        string     `json:"habit_id"`
+	UserID         string     `json:"user_id"`
+	Status         string     `json:"status"`
+	StartedAt      time.Time  `json:"started_at"`
+	ResumedAt      *time.Time `json:"resumed_at"`
+	ElapsedSeconds int        `json:"elapsed_seconds"`
+	StoppedAt      *time.Time `json:"stopped_at"`
+	// The completion the session's minutes were added to once it stopped
+	CompletionID *string   `json:"completion_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NewTimerSession starts a running session at now
+func NewTimerSession(id, habitID, userID string, now time.Time) *TimerSession {
+	return &TimerSession{
+		ID:        id,
+		HabitID:   habitID,
+		UserID:    userID,
+		Status:    "running",
+		StartedAt: now,
+		ResumedAt: &now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (s *TimerSession) IsRunning() bool {
+	return s.Status == "running"
+}
+
+func (s *TimerSession) IsStopped() bool {
+	return s.Status == "stopped"
+}
+
+// Elapsed returns the time the session has been running as of now, excluding pauses
+func (s *TimerSession) Elapsed(now time.Time) time.Duration {
+	elapsed := time.Duration(s.ElapsedSeconds) * time.Second
+	if s.IsRunning() && s.ResumedAt != nil && now.After(*s.ResumedAt) {
+		elapsed += now.Sub(*s.ResumedAt).Truncate(time.Second)
+	}
+	return elapsed
+}
+
+// Minutes returns the whole minutes the session ran for
+func (s *TimerSession) Minutes() int {
+	return s.ElapsedSeconds / 60
+}
+
+// Pause closes the running segment
+func (s *TimerSession) Pause(now time.Time) error {
+	if !s.IsRunning() {
+		return ErrTimerNotRunning
+	}
+	s.ElapsedSeconds = int(s.Elapsed(now).Seconds())
+	s.ResumedAt = nil
+	s.Status = "paused"
+	s.UpdatedAt = now
+	return nil
+}
+
+// Resume starts a new segment of a paused session
+func (s *TimerSession) Resume(now time.Time) error {
+	if s.IsStopped() {
+		return ErrTimerStopped
+	}
+	if s.IsRunning() {
+		return nil
+	}
+	s.ResumedAt = &now
+	s.Status = "running"
+	s.UpdatedAt = now
+	return nil
+}
+
+// Stop ends a running or paused session for good
+func (s *TimerSession) Stop(now time.Time) error {
+	if s.IsStopped() {
+		return ErrTimerStopped
+	}
+	s.ElapsedSeconds = int(s.Elapsed(now).Seconds())
+	s.ResumedAt = nil
+	s.StoppedAt = &now
+	s.Status = "stopped"
+	s.UpdatedAt = now
+	return nil
+}
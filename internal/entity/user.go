@@ -6,23 +6,32 @@ import (
 	"time"
 )
 
+// DefaultTimezone is used for users that have not picked a timezone yet
+const DefaultTimezone = "UTC"
+
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	GoogleID  string    `json:"-"`
-	Picture   string    `json:"picture"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Picture         string     `json:"picture"`
+	Timezone        string     `json:"timezone"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// NormalizeEmail returns email the way it is stored, so addresses that only differ in
+// case or surrounding spaces belong to the same user
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
-func NewUser(id, email, name, picture, googleID string) (*User, error) {
-	if strings.TrimSpace(email) == "" {
+// NewUser creates a user. How they log in is recorded separately, see UserIdentity.
+func NewUser(id, email, name, picture string) (*User, error) {
+	email = NormalizeEmail(email)
+	if email == "" {
 		return nil, errors.New("email cannot be empty")
 	}
-	if strings.TrimSpace(googleID) == "" {
-		return nil, errors.New("googleID cannot be empty")
-	}
 
 	now := time.Now()
 	return &User{
@@ -30,8 +39,46 @@ func NewUser(id, email, name, picture, googleID string) (*User, error) {
 		Email:     email,
 		Name:      name,
 		Picture:   picture,
-		GoogleID:  googleID,
+		Timezone:  DefaultTimezone,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
 }
+
+// IsEmailVerified reports whether the user proved they own their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// VerifyEmail marks the user's email address as verified at now, unless it already was
+func (u *User) VerifyEmail(now time.Time) {
+	if u.EmailVerifiedAt == nil {
+		u.EmailVerifiedAt = &now
+	}
+}
+
+// SetTimezone sets the user's IANA timezone, e.g. "Europe/Istanbul"
+func (u *User) SetTimezone(timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return errors.New("invalid timezone")
+	}
+	u.Timezone = timezone
+	return nil
+}
+
+// Location returns the user's timezone, falling back to UTC if it cannot be loaded
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Today returns the user's local calendar day at the given instant
+func (u *User) Today(now time.Time) time.Time {
+	return DateOnly(now.In(u.Location()))
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrLastLoginMethod = errors.New("cannot remove the last way to log in")
+
+// UserIdentity links a user to their account at an identity provider. A user can log in
+// with any of their identities, at most one per provider.
+type UserIdentity struct {
+	ID       string `json:"id"`
+	UserID   string `json:"user_id"`
+	Provider string `json:"provider"`
+	// The user's ID at the provider
+	Subject   string    `json:"-"`
+	Email     *string   `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewUserIdentity(id, userID, provider, subject, email string) (*UserIdentity, error) {
+	if strings.TrimSpace(provider) == "" {
+		return nil, errors.New("provider cannot be empty")
+	}
+	if strings.TrimSpace(subject) == "" {
+		return nil, errors.New("subject cannot be empty")
+	}
+
+	identity := &UserIdentity{
+		ID:        id,
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   subject,
+		CreatedAt: time.Now(),
+	}
+	if email != "" {
+		identity.Email = &email
+	}
+
+	return identity, nil
+}
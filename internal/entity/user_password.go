@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrEmailNotVerified   = errors.New("email address is not verified")
+)
+
+// UserPassword is the password a user can log in with besides their identities
+type UserPassword struct {
+	UserID string `json:"user_id"`
+	// The argon2id hash of the password, see utils.HashPassword
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func NewUserPassword(userID, passwordHash string, now time.Time) *UserPassword {
+	return &UserPassword{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
@@ -1,150 +1,228 @@
 package handler
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
 	"github.com/uygardeniz/habit-tracker/internal/config"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/oidc"
 	authUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/auth"
+	identityUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/identity"
 	userUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/user"
 	"github.com/uygardeniz/habit-tracker/internal/utils"
 )
 
-var (
-	frontendURL       = config.GetFrontendURL()
-	googleOauthConfig = config.GetGoogleOauthConfig()
-	oauthStateString  = "random"
-)
+var frontendURL = config.GetFrontendURL()
+
+// oauthStateCookie keeps the signed state and PKCE verifier of a login in progress
+const oauthStateCookie = "oauth_state"
+
+// oauthStateCookiePath scopes the state cookie to the provider's callback
+func oauthStateCookiePath(provider oidc.Provider) string {
+	return "/api/auth/" + provider.Name()
+}
+
+// startOAuthFlow sets the state cookie for a login with the provider, or for linking it
+// to the account of linkUserID when not empty, and returns the URL to send the browser to
+func startOAuthFlow(w http.ResponseWriter, r *http.Request, provider oidc.Provider, linkUserID string) (string, error) {
+	oauthState, err := utils.GenerateOAuthState(provider.Name(), linkUserID)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := provider.AuthCodeURL(r.Context(), oauthState.State, oauthState.Nonce, oauthState.Verifier)
+	if err != nil {
+		return "", err
+	}
+
+	stateCookie := http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    oauthState.Token,
+		Expires:  oauthState.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		Path:     oauthStateCookiePath(provider),
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, &stateCookie)
+
+	return url, nil
+}
 
 type AuthHandler struct {
-	logger                           *log.Logger
-	loginOrRegisterGoogleUserUsecase *authUsecase.LoginOrRegisterGoogleUserUsecase
-	getUserByIDUsecase               *userUsecase.GetUserByIDUsecase
+	logger                     *log.Logger
+	providers                  *oidc.Registry
+	loginOrRegisterUserUsecase *authUsecase.LoginOrRegisterUserUsecase
+	linkIdentityUsecase        *identityUsecase.LinkIdentityUsecase
+	getUserByIDUsecase         *userUsecase.GetUserByIDUsecase
+	issueRefreshTokenUsecase   *authUsecase.IssueRefreshTokenUsecase
+	rotateRefreshTokenUsecase  *authUsecase.RotateRefreshTokenUsecase
+	revokeRefreshTokenUsecase  *authUsecase.RevokeRefreshTokenUsecase
+	consumeOAuthStateUsecase   *authUsecase.ConsumeOAuthStateUsecase
 }
 
-func NewAuthHandler(logger *log.Logger, loginOrRegisterGoogleUserUsecase *authUsecase.LoginOrRegisterGoogleUserUsecase, getUserByIDUsecase *userUsecase.GetUserByIDUsecase) *AuthHandler {
+func NewAuthHandler(logger *log.Logger, providers *oidc.Registry, loginOrRegisterUserUsecase *authUsecase.LoginOrRegisterUserUsecase, linkIdentityUsecase *identityUsecase.LinkIdentityUsecase, getUserByIDUsecase *userUsecase.GetUserByIDUsecase, issueRefreshTokenUsecase *authUsecase.IssueRefreshTokenUsecase, rotateRefreshTokenUsecase *authUsecase.RotateRefreshTokenUsecase, revokeRefreshTokenUsecase *authUsecase.RevokeRefreshTokenUsecase, consumeOAuthStateUsecase *authUsecase.ConsumeOAuthStateUsecase) *AuthHandler {
 	return &AuthHandler{
-		logger:                           logger,
-		loginOrRegisterGoogleUserUsecase: loginOrRegisterGoogleUserUsecase,
-		getUserByIDUsecase:               getUserByIDUsecase,
+		logger:                     logger,
+		providers:                  providers,
+		loginOrRegisterUserUsecase: loginOrRegisterUserUsecase,
+		linkIdentityUsecase:        linkIdentityUsecase,
+		getUserByIDUsecase:         getUserByIDUsecase,
+		issueRefreshTokenUsecase:   issueRefreshTokenUsecase,
+		rotateRefreshTokenUsecase:  rotateRefreshTokenUsecase,
+		revokeRefreshTokenUsecase:  revokeRefreshTokenUsecase,
+		consumeOAuthStateUsecase:   consumeOAuthStateUsecase,
 	}
 }
 
-func (h *AuthHandler) HandleGoogleLogin(w http.ResponseWriter, r *http.Request) {
-	url := googleOauthConfig.AuthCodeURL(oauthStateString)
-	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
+// HandleProviders lists the identity providers users can log in with
+func (h *AuthHandler) HandleProviders(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"providers": h.providers.Names()}, h.logger)
 }
 
-func (h *AuthHandler) HandleGoogleCallback(w http.ResponseWriter, r *http.Request) {
-	state := r.FormValue("state")
-	if state != oauthStateString {
-		h.logger.Printf("invalid oauth state, expected '%s', got '%s'\n", oauthStateString, state)
-		http.Redirect(w, r, frontendURL, http.StatusTemporaryRedirect)
+// HandleLogin starts a login with the provider in the path
+func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	provider, err := h.providers.Get(r.PathValue("provider"))
+	if err != nil {
+		utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "unknown_provider"}, h.logger)
 		return
 	}
 
-	code := r.FormValue("code")
-	if code == "" {
-		h.logger.Printf("no authorization code received")
-		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "no_authorization_code"}, h.logger)
+	url, err := startOAuthFlow(w, r, provider, "")
+	if err != nil {
+		h.logger.Printf("failed to start %s login: %s\n", provider.Name(), err.Error())
+		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Internal server error", frontendURL), http.StatusTemporaryRedirect)
 		return
 	}
 
-	token, err := googleOauthConfig.Exchange(context.Background(), code)
+	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
+}
+
+// HandleCallback completes a login, or the linking of a provider to an account, started
+// in the same browser. Callbacks with a missing, expired, mismatched or already used
+// state are rejected.
+func (h *AuthHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	provider, err := h.providers.Get(r.PathValue("provider"))
 	if err != nil {
-		h.logger.Printf("code exchange failed with '%s'\n", err)
-		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "code_exchange_failed"}, h.logger)
+		utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "unknown_provider"}, h.logger)
 		return
 	}
 
-	response, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + token.AccessToken)
+	state := r.FormValue("state")
+
+	cookie, err := r.Cookie(oauthStateCookie)
 	if err != nil {
-		h.logger.Printf("failed getting user info: %s\n", err.Error())
-		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed_to_get_user_info"}, h.logger)
+		h.logger.Printf("oauth state cookie not found\n")
+		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Login expired", frontendURL), http.StatusTemporaryRedirect)
 		return
 	}
-	defer response.Body.Close()
 
-	contents, err := io.ReadAll(response.Body)
+	// The state is single use, so drop the cookie whatever the outcome
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		Path:     oauthStateCookiePath(provider),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	oauthState, err := utils.ValidateOAuthState(cookie.Value, provider.Name(), state)
 	if err != nil {
-		h.logger.Printf("failed reading response body: %s\n", err.Error())
-		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed_to_read_user_info"}, h.logger)
+		h.logger.Printf("invalid oauth state: %s\n", err.Error())
+		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Login expired", frontendURL), http.StatusTemporaryRedirect)
 		return
 	}
 
-	h.logger.Printf("user info: %s\n", string(contents))
-	var userInfo struct {
-		ID      string `json:"id"`
-		Email   string `json:"email"`
-		Name    string `json:"name"`
-		Picture string `json:"picture"`
-	}
-	err = json.Unmarshal(contents, &userInfo)
+	unused, err := h.consumeOAuthStateUsecase.Execute(r.Context(), oauthState)
 	if err != nil {
-		h.logger.Printf("failed to unmarshal user info: %s\n", err.Error())
+		h.logger.Printf("failed to consume oauth state: %s\n", err.Error())
 		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Internal server error", frontendURL), http.StatusTemporaryRedirect)
 		return
 	}
+	if !unused {
+		h.logger.Printf("oauth state was already used\n")
+		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Login expired", frontendURL), http.StatusTemporaryRedirect)
+		return
+	}
 
-	user, err := h.loginOrRegisterGoogleUserUsecase.Execute(r.Context(), userInfo.ID, userInfo.Email, userInfo.Name, userInfo.Picture)
+	code := r.FormValue("code")
+	if code == "" {
+		h.logger.Printf("no authorization code received")
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "no_authorization_code"}, h.logger)
+		return
+	}
+
+	profile, err := provider.Exchange(r.Context(), code, oauthState.Verifier, oauthState.Nonce)
 	if err != nil {
-		h.logger.Printf("failed to login or register user: %s\n", err.Error())
-		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Internal server error", frontendURL), http.StatusTemporaryRedirect)
+		h.logger.Printf("%s login failed: %s\n", provider.Name(), err.Error())
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "code_exchange_failed"}, h.logger)
+		return
+	}
+
+	if oauthState.LinkUserID != "" {
+		h.linkIdentity(w, r, provider.Name(), oauthState.LinkUserID, profile)
 		return
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID)
+	user, err := h.loginOrRegisterUserUsecase.Execute(r.Context(), provider.Name(), profile)
 	if err != nil {
-		h.logger.Printf("failed to generate refresh token: %s\n", err.Error())
-		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Internal server error", frontendURL), http.StatusTemporaryRedirect)
+		switch err {
+		case apperrors.ErrAlreadyExists:
+			http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=An account with this email already exists. Log in and link this provider from your settings", frontendURL), http.StatusTemporaryRedirect)
+		case apperrors.ErrInvalidInput:
+			http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Email address is required", frontendURL), http.StatusTemporaryRedirect)
+		default:
+			h.logger.Printf("failed to login or register user: %s\n", err.Error())
+			http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Internal server error", frontendURL), http.StatusTemporaryRedirect)
+		}
 		return
 	}
 
-	refreshTokenCookie := http.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		Expires:  time.Now().Add(24 * 7 * time.Hour),
-		HttpOnly: true,
-		Secure:   r.TLS != nil,
-		Path:     "/api/auth",
-		SameSite: http.SameSiteLaxMode,
+	refreshToken, err := h.issueRefreshTokenUsecase.Execute(r.Context(), user.ID, r.UserAgent(), utils.ClientIP(r))
+	if err != nil {
+		h.logger.Printf("failed to generate refresh token: %s\n", err.Error())
+		http.Redirect(w, r, fmt.Sprintf("%s/auth?auth_error=Internal server error", frontendURL), http.StatusTemporaryRedirect)
+		return
 	}
-	http.SetCookie(w, &refreshTokenCookie)
+	setRefreshTokenCookie(w, r, refreshToken)
 
 	h.logger.Printf("Authentication successful. UserID: %s. Redirecting to frontend.", user.ID)
 	http.Redirect(w, r, frontendURL, http.StatusTemporaryRedirect)
 }
 
-func (h *AuthHandler) HandleRefreshToken(w http.ResponseWriter, r *http.Request) {
-	cookie, err := r.Cookie("refresh_token")
+// linkIdentity completes the linking of a provider started from the user's settings
+func (h *AuthHandler) linkIdentity(w http.ResponseWriter, r *http.Request, provider, userID string, profile *oidc.Profile) {
+	_, err := h.linkIdentityUsecase.Execute(r.Context(), userID, provider, profile)
 	if err != nil {
-		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "refresh token not found"}, h.logger)
+		switch err {
+		case apperrors.ErrAlreadyExists:
+			http.Redirect(w, r, fmt.Sprintf("%s/settings?link_error=This account is already linked", frontendURL), http.StatusTemporaryRedirect)
+		default:
+			h.logger.Printf("failed to link %s identity: %s\n", provider, err.Error())
+			http.Redirect(w, r, fmt.Sprintf("%s/settings?link_error=Internal server error", frontendURL), http.StatusTemporaryRedirect)
+		}
 		return
 	}
 
-	refreshTokenString := cookie.Value
-	refreshToken, err := utils.ValidateToken(refreshTokenString, os.Getenv("JWT_REFRESH_SECRET"))
-	if err != nil || !refreshToken.Valid {
-		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "invalid refresh token"}, h.logger)
-		return
-	}
+	h.logger.Printf("Identity linked successfully. Provider: %s, UserID: %s", provider, userID)
+	http.Redirect(w, r, fmt.Sprintf("%s/settings", frontendURL), http.StatusTemporaryRedirect)
+}
 
-	claims, ok := refreshToken.Claims.(jwt.MapClaims)
+// HandleRefreshToken exchanges the refresh token cookie for a new access token and
+// rotates the refresh token
+func (h *AuthHandler) HandleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := h.rotateRefreshToken(w, r)
 	if !ok {
-		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to parse token claims"}, h.logger)
 		return
 	}
 
-	userID := claims["sub"].(string)
-
-	newAccessToken, err := utils.GenerateAccessToken(userID)
+	newAccessToken, err := utils.GenerateAccessToken(refreshToken.UserID, refreshToken.FamilyID)
 	if err != nil {
 		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to generate new access token"}, h.logger)
 		return
@@ -153,74 +231,95 @@ func (h *AuthHandler) HandleRefreshToken(w http.ResponseWriter, r *http.Request)
 	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"access_token": newAccessToken}, h.logger)
 }
 
+// HandleLogout revokes the refresh token's family so it can't be used anymore
 func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
-
-	cookie := http.Cookie{
-		Name: "refresh_token",
-
-		Value:    "",
-		Expires:  time.Unix(0, 0),
-		HttpOnly: true,
-		Secure:   false,
-		Path:     "/api/auth",
-		SameSite: http.SameSiteLaxMode,
+	if cookie, err := r.Cookie("refresh_token"); err == nil {
+		if err := h.revokeRefreshTokenUsecase.Execute(r.Context(), cookie.Value); err != nil {
+			h.logger.Printf("failed to revoke refresh token: %s\n", err.Error())
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to log out"}, h.logger)
+			return
+		}
 	}
-	http.SetCookie(w, &cookie)
+
+	clearRefreshTokenCookie(w, r)
 
 	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"message": "logged out successfully"}, h.logger)
 }
 
+// HandleGetUserAndAccessToken returns the logged in user with a new access token and
+// rotates the refresh token
 func (h *AuthHandler) HandleGetUserAndAccessToken(w http.ResponseWriter, r *http.Request) {
-	cookie, err := r.Cookie("refresh_token")
-	if err != nil {
-		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "refresh token not found"}, h.logger)
-		return
-	}
-
-	refreshTokenString := cookie.Value
-	refreshToken, err := utils.ValidateToken(refreshTokenString, os.Getenv("JWT_REFRESH_SECRET"))
-	if err != nil || !refreshToken.Valid {
-		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "invalid refresh token"}, h.logger)
-		return
-	}
-
-	claims, ok := refreshToken.Claims.(jwt.MapClaims)
+	refreshToken, ok := h.rotateRefreshToken(w, r)
 	if !ok {
-		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to parse token claims"}, h.logger)
 		return
 	}
 
-	userID := claims["sub"].(string)
-
-	user, err := h.getUserByIDUsecase.Execute(r.Context(), userID)
+	user, err := h.getUserByIDUsecase.Execute(r.Context(), refreshToken.UserID)
 	if err != nil {
 		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to get user"}, h.logger)
 		return
 	}
 
-	accessToken, err := utils.GenerateAccessToken(userID)
+	accessToken, err := utils.GenerateAccessToken(refreshToken.UserID, refreshToken.FamilyID)
 	if err != nil {
 		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to generate access token"}, h.logger)
 		return
 	}
 
-	newRefreshToken, err := utils.GenerateRefreshToken(userID)
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"user": user, "access_token": accessToken}, h.logger)
+}
 
+// rotateRefreshToken replaces the refresh token cookie with the next token of its family
+// and returns the new token's record. It writes the error response itself and reports
+// whether the request can go on.
+func (h *AuthHandler) rotateRefreshToken(w http.ResponseWriter, r *http.Request) (*entity.RefreshToken, bool) {
+	cookie, err := r.Cookie("refresh_token")
 	if err != nil {
-		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to generate refresh token"}, h.logger)
-		return
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "refresh token not found"}, h.logger)
+		return nil, false
 	}
 
+	next, refreshToken, err := h.rotateRefreshTokenUsecase.Execute(r.Context(), cookie.Value, r.UserAgent(), utils.ClientIP(r))
+	if err != nil {
+		switch err {
+		case entity.ErrRefreshTokenReused:
+			h.logger.Printf("refresh token reuse detected, token family revoked\n")
+			utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "invalid refresh token"}, h.logger)
+		case apperrors.ErrUnauthorized:
+			utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "invalid refresh token"}, h.logger)
+		default:
+			h.logger.Printf("failed to rotate refresh token: %s\n", err.Error())
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "failed to generate refresh token"}, h.logger)
+		}
+		return nil, false
+	}
+
+	setRefreshTokenCookie(w, r, refreshToken)
+	return next, true
+}
+
+func setRefreshTokenCookie(w http.ResponseWriter, r *http.Request, refreshToken string) {
 	refreshTokenCookie := http.Cookie{
 		Name:     "refresh_token",
-		Value:    newRefreshToken,
-		Expires:  time.Now().Add(24 * 7 * time.Hour),
+		Value:    refreshToken,
+		Expires:  time.Now().Add(entity.RefreshTokenTTL),
 		HttpOnly: true,
 		Secure:   r.TLS != nil,
 		Path:     "/api/auth",
 		SameSite: http.SameSiteLaxMode,
 	}
 	http.SetCookie(w, &refreshTokenCookie)
+}
 
-	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"user": user, "access_token": accessToken}, h.logger)
+func clearRefreshTokenCookie(w http.ResponseWriter, r *http.Request) {
+	cookie := http.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		Path:     "/api/auth",
+		SameSite: http.SameSiteLaxMode,
+	}
+	http.SetCookie(w, &cookie)
 }
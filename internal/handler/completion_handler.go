@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -21,6 +22,8 @@ type CompletionHandler struct {
 	getCompletionsUsecase   *completionUsecase.GetCompletionsUsecase
 	updateCompletionUsecase *completionUsecase.UpdateCompletionUsecase
 	deleteCompletionUsecase *completionUsecase.DeleteCompletionUsecase
+	checkInUsecase          *completionUsecase.CheckInUsecase
+	undoCheckInUsecase      *completionUsecase.UndoCheckInUsecase
 	logger                  *log.Logger
 	v                       *validator.Validate
 }
@@ -31,6 +34,8 @@ func NewCompletionHandler(
 	getCompletionsUsecase *completionUsecase.GetCompletionsUsecase,
 	updateCompletionUsecase *completionUsecase.UpdateCompletionUsecase,
 	deleteCompletionUsecase *completionUsecase.DeleteCompletionUsecase,
+	checkInUsecase *completionUsecase.CheckInUsecase,
+	undoCheckInUsecase *completionUsecase.UndoCheckInUsecase,
 	logger *log.Logger,
 	v *validator.Validate,
 ) *CompletionHandler {
@@ -40,6 +45,8 @@ func NewCompletionHandler(
 		getCompletionsUsecase:   getCompletionsUsecase,
 		updateCompletionUsecase: updateCompletionUsecase,
 		deleteCompletionUsecase: deleteCompletionUsecase,
+		checkInUsecase:          checkInUsecase,
+		undoCheckInUsecase:      undoCheckInUsecase,
 		logger:                  logger,
 		v:                       v,
 	}
@@ -161,7 +168,7 @@ func (h *CompletionHandler) GetCompletions(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	completions, err := h.getCompletionsUsecase.Execute(r.Context(), userID, query)
+	completions, timeOff, err := h.getCompletionsUsecase.Execute(r.Context(), userID, query)
 	if err != nil {
 		h.logger.Printf("Error getting completions for user %s: %v", userID, err)
 		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
@@ -173,7 +180,11 @@ func (h *CompletionHandler) GetCompletions(w http.ResponseWriter, r *http.Reques
 		responses = append(responses, toCompletionResponseDTO(completion))
 	}
 
-	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"completions": responses}, h.logger)
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{
+		"completions": responses,
+		"skips":       toSkipResponseDTOs(timeOff.Skips),
+		"pauses":      toPauseResponseDTOs(timeOff.Pauses),
+	}, h.logger)
 }
 
 func (h *CompletionHandler) UpdateCompletion(w http.ResponseWriter, r *http.Request) {
@@ -247,6 +258,99 @@ func (h *CompletionHandler) DeleteCompletion(w http.ResponseWriter, r *http.Requ
 	utils.WriteJSON(w, http.StatusNoContent, nil, h.logger)
 }
 
+func (h *CompletionHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	req, ok := h.decodeCheckin(w, r)
+	if !ok {
+		return
+	}
+
+	completion, err := h.checkInUsecase.Execute(r.Context(), habitID, userID, req)
+	if err != nil {
+		switch err {
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_input"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "habit not found"}, h.logger)
+		default:
+			h.logger.Printf("Error checking in: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	response := toCompletionResponseDTO(completion)
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"completion": response}, h.logger)
+}
+
+func (h *CompletionHandler) UndoCheckIn(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	req, ok := h.decodeCheckin(w, r)
+	if !ok {
+		return
+	}
+
+	completion, err := h.undoCheckInUsecase.Execute(r.Context(), habitID, userID, req)
+	if err != nil {
+		switch err {
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_input"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "no check-ins found for this date"}, h.logger)
+		default:
+			h.logger.Printf("Error undoing check-in: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	// The completion is gone once all of the day's check-ins have been undone
+	if completion == nil {
+		utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"completion": nil}, h.logger)
+		return
+	}
+
+	response := toCompletionResponseDTO(completion)
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"completion": response}, h.logger)
+}
+
+// decodeCheckin reads an optional check-in body; an empty body checks in once for today
+func (h *CompletionHandler) decodeCheckin(w http.ResponseWriter, r *http.Request) (dto.CheckinDTO, bool) {
+	var req dto.CheckinDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.logger.Printf("Failed to decode request: %v", err)
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_request_format"}, h.logger)
+		return req, false
+	}
+
+	if err := h.v.Struct(&req); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return req, false
+	}
+
+	return req, true
+}
+
 func toCompletionResponseDTO(completion *entity.HabitCompletion) dto.CompletionResponseDTO {
 	return dto.CompletionResponseDTO{
 		ID:             completion.ID,
@@ -255,6 +359,11 @@ func toCompletionResponseDTO(completion *entity.HabitCompletion) dto.CompletionR
 		CompletedAt:    completion.CompletedAt,
 		CompletionDate: completion.CompletionDate,
 		Count:          completion.Count,
+		Value:          completion.Value,
+		TargetCount:    completion.TargetCount,
+		TargetValue:    completion.TargetValue,
+		Progress:       completion.Progress(),
+		IsFulfilled:    completion.IsFulfilled(),
 		Notes:          completion.Notes,
 		CreatedAt:      completion.CreatedAt,
 	}
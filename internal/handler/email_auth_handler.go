@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	authUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/auth"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+// EmailAuthHandler handles logging in with an email address, by password or by a magic
+// link sent to it. Successful logins set the refresh token cookie like the identity
+// provider callbacks do, after which the frontend gets the user from /api/auth/session.
+type EmailAuthHandler struct {
+	registerWithPasswordUsecase  *authUsecase.RegisterWithPasswordUsecase
+	sendVerificationEmailUsecase *authUsecase.SendVerificationEmailUsecase
+	verifyEmailUsecase           *authUsecase.VerifyEmailUsecase
+	loginWithPasswordUsecase     *authUsecase.LoginWithPasswordUsecase
+	requestPasswordResetUsecase  *authUsecase.RequestPasswordResetUsecase
+	resetPasswordUsecase         *authUsecase.ResetPasswordUsecase
+	requestMagicLinkUsecase      *authUsecase.RequestMagicLinkUsecase
+	loginWithMagicLinkUsecase    *authUsecase.LoginWithMagicLinkUsecase
+	changePasswordUsecase        *authUsecase.ChangePasswordUsecase
+	issueRefreshTokenUsecase     *authUsecase.IssueRefreshTokenUsecase
+	logger                       *log.Logger
+	v                            *validator.Validate
+}
+
+func NewEmailAuthHandler(
+	registerWithPasswordUsecase *authUsecase.RegisterWithPasswordUsecase,
+	sendVerificationEmailUsecase *authUsecase.SendVerificationEmailUsecase,
+	verifyEmailUsecase *authUsecase.VerifyEmailUsecase,
+	loginWithPasswordUsecase *authUsecase.LoginWithPasswordUsecase,
+	requestPasswordResetUsecase *authUsecase.RequestPasswordResetUsecase,
+	resetPasswordUsecase *authUsecase.ResetPasswordUsecase,
+	requestMagicLinkUsecase *authUsecase.RequestMagicLinkUsecase,
+	loginWithMagicLinkUsecase *authUsecase.LoginWithMagicLinkUsecase,
+	changePasswordUsecase *authUsecase.ChangePasswordUsecase,
+	issueRefreshTokenUsecase *authUsecase.IssueRefreshTokenUsecase,
+	logger *log.Logger,
+	v *validator.Validate,
+) *EmailAuthHandler {
+	return &EmailAuthHandler{
+		registerWithPasswordUsecase:  registerWithPasswordUsecase,
+		sendVerificationEmailUsecase: sendVerificationEmailUsecase,
+		verifyEmailUsecase:           verifyEmailUsecase,
+		loginWithPasswordUsecase:     loginWithPasswordUsecase,
+		requestPasswordResetUsecase:  requestPasswordResetUsecase,
+		resetPasswordUsecase:         resetPasswordUsecase,
+		requestMagicLinkUsecase:      requestMagicLinkUsecase,
+		loginWithMagicLinkUsecase:    loginWithMagicLinkUsecase,
+		changePasswordUsecase:        changePasswordUsecase,
+		issueRefreshTokenUsecase:     issueRefreshTokenUsecase,
+		logger:                       logger,
+		v:                            v,
+	}
+}
+
+// Register signs up with an email address and password. The response is the same
+// whether or not the address already has an account.
+func (h *EmailAuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req dto.RegisterDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.registerWithPasswordUsecase.Execute(r.Context(), req.Email, req.Password, req.Name); err != nil {
+		h.logger.Printf("Error registering user: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, utils.APIResponse{"message": "check your email to verify your account"}, h.logger)
+}
+
+// ResendVerificationEmail sends a new verification link to an unverified address
+func (h *EmailAuthHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.EmailDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.sendVerificationEmailUsecase.Execute(r.Context(), req.Email); err != nil {
+		h.logger.Printf("Error sending verification email: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, utils.APIResponse{"message": "check your email to verify your account"}, h.logger)
+}
+
+// VerifyEmail verifies an email address with the token from a verification link and
+// the password the user signed up with, if any
+func (h *EmailAuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.verifyEmailUsecase.Execute(r.Context(), req.Token, req.Password); err != nil {
+		if err == entity.ErrInvalidCredentials {
+			utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "invalid_credentials"}, h.logger)
+			return
+		}
+		h.writeTokenError(w, err, "Error verifying email")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"message": "email verified"}, h.logger)
+}
+
+func (h *EmailAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	user, err := h.loginWithPasswordUsecase.Execute(r.Context(), req.Email, req.Password)
+	if err != nil {
+		switch err {
+		case entity.ErrInvalidCredentials:
+			utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "invalid_credentials"}, h.logger)
+		case entity.ErrEmailNotVerified:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "email_not_verified"}, h.logger)
+		default:
+			h.logger.Printf("Error logging in with password: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.startSession(w, r, user)
+}
+
+// ForgotPassword emails a password reset link. The response is the same whether or
+// not the address has an account.
+func (h *EmailAuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.EmailDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.requestPasswordResetUsecase.Execute(r.Context(), req.Email); err != nil {
+		h.logger.Printf("Error requesting password reset: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, utils.APIResponse{"message": "check your email to reset your password"}, h.logger)
+}
+
+// ResetPassword sets a new password with the token from a reset link and logs the user
+// out everywhere
+func (h *EmailAuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.resetPasswordUsecase.Execute(r.Context(), req.Token, req.Password); err != nil {
+		h.writeTokenError(w, err, "Error resetting password")
+		return
+	}
+	clearRefreshTokenCookie(w, r)
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"message": "password reset"}, h.logger)
+}
+
+// RequestMagicLink emails a link that logs in without a password. The response is the
+// same whether or not the address has an account.
+func (h *EmailAuthHandler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req dto.EmailDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.requestMagicLinkUsecase.Execute(r.Context(), req.Email); err != nil {
+		h.logger.Printf("Error requesting magic link: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, utils.APIResponse{"message": "check your email for a login link"}, h.logger)
+}
+
+func (h *EmailAuthHandler) LoginWithMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req dto.EmailTokenDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	user, err := h.loginWithMagicLinkUsecase.Execute(r.Context(), req.Token)
+	if err != nil {
+		h.writeTokenError(w, err, "Error logging in with magic link")
+		return
+	}
+
+	h.startSession(w, r, user)
+}
+
+// ChangePassword sets the authenticated user's password, letting users who log in with
+// identity providers add one
+func (h *EmailAuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	var req dto.ChangePasswordDTO
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	err = h.changePasswordUsecase.Execute(r.Context(), userID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch err {
+		case entity.ErrInvalidCredentials:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "invalid_current_password"}, h.logger)
+		default:
+			h.logger.Printf("Error changing password: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.logger.Printf("Password changed successfully. UserID: %s", userID)
+	utils.WriteJSON(w, http.StatusNoContent, nil, h.logger)
+}
+
+// decode reads and validates the request body into req. It writes the error response
+// itself and reports whether the request can go on.
+func (h *EmailAuthHandler) decode(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		h.logger.Printf("Failed to decode request: %v", err)
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_request_format"}, h.logger)
+		return false
+	}
+
+	if err := h.v.Struct(req); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return false
+	}
+
+	return true
+}
+
+// startSession logs the user in on this client by setting the refresh token cookie
+func (h *EmailAuthHandler) startSession(w http.ResponseWriter, r *http.Request, user *entity.User) {
+	refreshToken, err := h.issueRefreshTokenUsecase.Execute(r.Context(), user.ID, r.UserAgent(), utils.ClientIP(r))
+	if err != nil {
+		h.logger.Printf("Error generating refresh token: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+	setRefreshTokenCookie(w, r, refreshToken)
+
+	h.logger.Printf("Authentication successful. UserID: %s", user.ID)
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"message": "logged in successfully"}, h.logger)
+}
+
+func (h *EmailAuthHandler) writeTokenError(w http.ResponseWriter, err error, message string) {
+	switch err {
+	case entity.ErrInvalidEmailToken:
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_or_expired_token"}, h.logger)
+	default:
+		h.logger.Printf("%s: %v", message, err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+	}
+}
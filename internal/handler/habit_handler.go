@@ -15,13 +15,15 @@ import (
 )
 
 type HabitHandler struct {
-	createHabitUsecase     *habitUsecase.CreateHabitUsecase
-	getHabitUsecase        *habitUsecase.GetHabitUsecase
-	updateHabitUsecase     *habitUsecase.UpdateHabitUsecase
-	getHabitsByUserUsecase *habitUsecase.GetHabitsByUserUsecase
-	deleteHabitUsecase     *habitUsecase.DeleteHabitUsecase
-	logger                 *log.Logger
-	v                      *validator.Validate
+	createHabitUsecase      *habitUsecase.CreateHabitUsecase
+	getHabitUsecase         *habitUsecase.GetHabitUsecase
+	updateHabitUsecase      *habitUsecase.UpdateHabitUsecase
+	getHabitsByUserUsecase  *habitUsecase.GetHabitsByUserUsecase
+	deleteHabitUsecase      *habitUsecase.DeleteHabitUsecase
+	getDueHabitsUsecase     *habitUsecase.GetDueHabitsUsecase
+	getStreakFreezesUsecase *habitUsecase.GetStreakFreezesUsecase
+	logger                  *log.Logger
+	v                       *validator.Validate
 }
 
 func NewHabitHandler(
@@ -30,17 +32,21 @@ func NewHabitHandler(
 	updateHabitUsecase *habitUsecase.UpdateHabitUsecase,
 	getHabitsByUserUsecase *habitUsecase.GetHabitsByUserUsecase,
 	deleteHabitUsecase *habitUsecase.DeleteHabitUsecase,
+	getDueHabitsUsecase *habitUsecase.GetDueHabitsUsecase,
+	getStreakFreezesUsecase *habitUsecase.GetStreakFreezesUsecase,
 	logger *log.Logger,
 	v *validator.Validate,
 ) *HabitHandler {
 	return &HabitHandler{
-		createHabitUsecase:     createHabitUsecase,
-		getHabitUsecase:        getHabitUsecase,
-		updateHabitUsecase:     updateHabitUsecase,
-		getHabitsByUserUsecase: getHabitsByUserUsecase,
-		deleteHabitUsecase:     deleteHabitUsecase,
-		logger:                 logger,
-		v:                      v,
+		createHabitUsecase:      createHabitUsecase,
+		getHabitUsecase:         getHabitUsecase,
+		updateHabitUsecase:      updateHabitUsecase,
+		getHabitsByUserUsecase:  getHabitsByUserUsecase,
+		deleteHabitUsecase:      deleteHabitUsecase,
+		getDueHabitsUsecase:     getDueHabitsUsecase,
+		getStreakFreezesUsecase: getStreakFreezesUsecase,
+		logger:                  logger,
+		v:                       v,
 	}
 }
 
@@ -183,6 +189,8 @@ func (h *HabitHandler) UpdateHabit(w http.ResponseWriter, r *http.Request) {
 			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_input"}, h.logger)
 		case apperrors.ErrNotFound:
 			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "not_found"}, h.logger)
+		case entity.ErrKindChangeWithHistory:
+			utils.WriteJSON(w, http.StatusConflict, utils.APIResponse{"error": err.Error()}, h.logger)
 		default:
 			h.logger.Printf("Error updating habit: %v", err)
 			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
@@ -229,6 +237,101 @@ func (h *HabitHandler) DeleteHabit(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *HabitHandler) GetDueHabits(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	dueHabits, err := h.getDueHabitsUsecase.Execute(r.Context(), userID, r.URL.Query().Get("date"))
+	if err != nil {
+		switch err {
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "date must be in YYYY-MM-DD format"}, h.logger)
+		default:
+			h.logger.Printf("Error getting due habits for user %s: %v", userID, err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	responses := []dto.DueHabitResponseDTO{}
+	for _, due := range dueHabits {
+		habitResponse, err := toHabitResponseDTO(due.Habit)
+		if err != nil {
+			h.logger.Printf("Failed to map habit to response DTO for habit %s: %v", due.Habit.ID, err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+			return
+		}
+
+		response := dto.DueHabitResponseDTO{
+			Habit:       habitResponse,
+			Date:        due.Date.Format("2006-01-02"),
+			Count:       due.Count(),
+			Value:       due.Value(),
+			TargetCount: due.TargetCount(),
+			TargetValue: due.TargetValue(),
+			Progress:    due.Progress(),
+			IsFulfilled: due.IsFulfilled(),
+		}
+		if due.Completion != nil {
+			response.CompletionID = &due.Completion.ID
+		}
+		if entity.IsPeriodQuota(due.Habit.ScheduleOn(due.Date).Frequency) {
+			periodCompleted := due.PeriodCompleted
+			response.PeriodCompleted = &periodCompleted
+		}
+
+		responses = append(responses, response)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"habits": responses}, h.logger)
+}
+
+func (h *HabitHandler) GetStreakFreezes(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	freezes, err := h.getStreakFreezesUsecase.Execute(r.Context(), habitID, userID)
+	if err != nil {
+		switch err {
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "habit not found"}, h.logger)
+		default:
+			h.logger.Printf("Error getting streak freezes for habit %s: %v", habitID, err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	response := dto.StreakFreezesResponseDTO{
+		HabitID:  habitID,
+		Balance:  freezes.Balance,
+		EarnDays: freezes.EarnDays,
+		History:  []dto.FreezeEventResponseDTO{},
+	}
+	for _, event := range freezes.Events {
+		response.History = append(response.History, dto.FreezeEventResponseDTO{
+			Date:    event.Date.Format("2006-01-02"),
+			Type:    event.Type,
+			Balance: event.Balance,
+			Streak:  event.Streak,
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"freezes": response}, h.logger)
+}
+
 // toHabitResponseDTO converts an entity.Habit to a dto.HabitResponseDTO
 func toHabitResponseDTO(habit *entity.Habit) (dto.HabitResponseDTO, error) {
 	response := dto.HabitResponseDTO{
@@ -239,8 +342,15 @@ func toHabitResponseDTO(habit *entity.Habit) (dto.HabitResponseDTO, error) {
 		Motivation:       habit.Motivation,
 		Color:            habit.Color,
 		Category:         habit.Category,
+		Kind:             habit.Kind,
+		Unit:             habit.Unit,
 		Frequency:        habit.Frequency,
 		TargetCount:      habit.TargetCount,
+		TargetValue:      habit.TargetValue,
+		PeriodTarget:     habit.PeriodTarget,
+		IntervalDays:     habit.IntervalDays,
+		AnchorDate:       habit.AnchorDate,
+		Recurrence:       habit.Recurrence,
 		CurrentStreak:    habit.CurrentStreak,
 		BestStreak:       habit.BestStreak,
 		TotalCompletions: habit.TotalCompletions,
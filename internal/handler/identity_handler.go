@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	"github.com/uygardeniz/habit-tracker/internal/oidc"
+	identityUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/identity"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type IdentityHandler struct {
+	providers             *oidc.Registry
+	getIdentitiesUsecase  *identityUsecase.GetIdentitiesUsecase
+	unlinkIdentityUsecase *identityUsecase.UnlinkIdentityUsecase
+	logger                *log.Logger
+}
+
+func NewIdentityHandler(
+	providers *oidc.Registry,
+	getIdentitiesUsecase *identityUsecase.GetIdentitiesUsecase,
+	unlinkIdentityUsecase *identityUsecase.UnlinkIdentityUsecase,
+	logger *log.Logger,
+) *IdentityHandler {
+	return &IdentityHandler{
+		providers:             providers,
+		getIdentitiesUsecase:  getIdentitiesUsecase,
+		unlinkIdentityUsecase: unlinkIdentityUsecase,
+		logger:                logger,
+	}
+}
+
+func (h *IdentityHandler) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	identities, err := h.getIdentitiesUsecase.Execute(r.Context(), userID)
+	if err != nil {
+		h.logger.Printf("Error getting identities: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	responses := make([]dto.IdentityResponseDTO, 0, len(identities))
+	for _, identity := range identities {
+		responses = append(responses, dto.IdentityResponseDTO{
+			ID:        identity.ID,
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"identities": responses}, h.logger)
+}
+
+// StartLinkIdentity starts linking the provider in the path to the user's account. It
+// returns the URL of the provider's login page for the browser to go to; the callback
+// then links the account the user logs in to.
+func (h *IdentityHandler) StartLinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	provider, err := h.providers.Get(r.PathValue("provider"))
+	if err != nil {
+		utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "unknown_provider"}, h.logger)
+		return
+	}
+
+	url, err := startOAuthFlow(w, r, provider, userID)
+	if err != nil {
+		h.logger.Printf("Error starting %s link: %v", provider.Name(), err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"url": url}, h.logger)
+}
+
+func (h *IdentityHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	identityID := r.PathValue("identityID")
+
+	err = h.unlinkIdentityUsecase.Execute(r.Context(), identityID, userID)
+	if err != nil {
+		switch err {
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "identity not found"}, h.logger)
+		case entity.ErrLastLoginMethod:
+			utils.WriteJSON(w, http.StatusConflict, utils.APIResponse{"error": err.Error()}, h.logger)
+		default:
+			h.logger.Printf("Error unlinking identity: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.logger.Printf("Identity unlinked successfully. IdentityID: %s, UserID: %s", identityID, userID)
+	utils.WriteJSON(w, http.StatusNoContent, nil, h.logger)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	sessionUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/session"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type SessionHandler struct {
+	getSessionsUsecase       *sessionUsecase.GetSessionsUsecase
+	revokeSessionUsecase     *sessionUsecase.RevokeSessionUsecase
+	revokeAllSessionsUsecase *sessionUsecase.RevokeAllSessionsUsecase
+	logger                   *log.Logger
+}
+
+func NewSessionHandler(
+	getSessionsUsecase *sessionUsecase.GetSessionsUsecase,
+	revokeSessionUsecase *sessionUsecase.RevokeSessionUsecase,
+	revokeAllSessionsUsecase *sessionUsecase.RevokeAllSessionsUsecase,
+	logger *log.Logger,
+) *SessionHandler {
+	return &SessionHandler{
+		getSessionsUsecase:       getSessionsUsecase,
+		revokeSessionUsecase:     revokeSessionUsecase,
+		revokeAllSessionsUsecase: revokeAllSessionsUsecase,
+		logger:                   logger,
+	}
+}
+
+func (h *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+	currentSessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	sessions, err := h.getSessionsUsecase.Execute(r.Context(), userID)
+	if err != nil {
+		h.logger.Printf("Error getting sessions: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	responses := make([]dto.SessionResponseDTO, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, dto.SessionResponseDTO{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			IsCurrent:  session.ID == currentSessionID,
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"sessions": responses}, h.logger)
+}
+
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	sessionID := r.PathValue("sessionID")
+
+	err = h.revokeSessionUsecase.Execute(r.Context(), sessionID, userID)
+	if err != nil {
+		switch err {
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "session not found"}, h.logger)
+		default:
+			h.logger.Printf("Error revoking session: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	if currentSessionID, _ := middleware.GetSessionIDFromContext(r.Context()); currentSessionID == sessionID {
+		clearRefreshTokenCookie(w, r)
+	}
+
+	h.logger.Printf("Session revoked successfully. SessionID: %s, UserID: %s", sessionID, userID)
+	utils.WriteJSON(w, http.StatusNoContent, nil, h.logger)
+}
+
+// RevokeAllSessions logs the user out on every device, this one included
+func (h *SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	if err := h.revokeAllSessionsUsecase.Execute(r.Context(), userID); err != nil {
+		h.logger.Printf("Error revoking sessions: %v", err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+	clearRefreshTokenCookie(w, r)
+
+	h.logger.Printf("All sessions revoked successfully. UserID: %s", userID)
+	utils.WriteJSON(w, http.StatusNoContent, nil, h.logger)
+}
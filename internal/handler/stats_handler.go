@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	statsUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/stats"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type StatsHandler struct {
+	getHeatmapUsecase      *statsUsecase.GetHeatmapUsecase
+	getHabitStatsUsecase   *statsUsecase.GetHabitStatsUsecase
+	getOverviewUsecase     *statsUsecase.GetOverviewUsecase
+	getCorrelationsUsecase *statsUsecase.GetCorrelationsUsecase
+	logger                 *log.Logger
+	v                      *validator.Validate
+}
+
+func NewStatsHandler(
+	getHeatmapUsecase *statsUsecase.GetHeatmapUsecase,
+	getHabitStatsUsecase *statsUsecase.GetHabitStatsUsecase,
+	getOverviewUsecase *statsUsecase.GetOverviewUsecase,
+	getCorrelationsUsecase *statsUsecase.GetCorrelationsUsecase,
+	logger *log.Logger,
+	v *validator.Validate,
+) *StatsHandler {
+	return &StatsHandler{
+		getHeatmapUsecase:      getHeatmapUsecase,
+		getHabitStatsUsecase:   getHabitStatsUsecase,
+		getOverviewUsecase:     getOverviewUsecase,
+		getCorrelationsUsecase: getCorrelationsUsecase,
+		logger:                 logger,
+		v:                      v,
+	}
+}
+
+func (h *StatsHandler) GetHeatmap(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	query := dto.HeatmapQueryDTO{}
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		query.From = &from
+	}
+
+	if to := r.URL.Query().Get("to"); to != "" {
+		query.To = &to
+	}
+
+	if habitID := r.URL.Query().Get("habit_id"); habitID != "" {
+		query.HabitID = &habitID
+	}
+
+	if err := h.v.Struct(&query); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return
+	}
+
+	heatmap, err := h.getHeatmapUsecase.Execute(r.Context(), userID, query)
+	if err != nil {
+		switch err {
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "from must not be after to, and the range can't exceed two years"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "habit not found"}, h.logger)
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		default:
+			h.logger.Printf("Error getting heatmap for user %s: %v", userID, err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	response := dto.HeatmapResponseDTO{
+		From: heatmap.From.Format("2006-01-02"),
+		To:   heatmap.To.Format("2006-01-02"),
+		Days: make(map[string]dto.HeatmapDayDTO, len(heatmap.Days)),
+	}
+	for _, day := range heatmap.Days {
+		response.Days[day.Date.Format("2006-01-02")] = dto.HeatmapDayDTO{
+			Count:     day.Count,
+			Value:     day.Value,
+			Fulfilled: day.Fulfilled,
+			Scheduled: day.Scheduled,
+			Slips:     day.Slips,
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"heatmap": response}, h.logger)
+}
+
+func (h *StatsHandler) GetHabitStats(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	query := dto.HabitStatsQueryDTO{}
+	if series := r.URL.Query().Get("series"); series != "" {
+		query.Series = &series
+	}
+
+	if err := h.v.Struct(&query); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return
+	}
+
+	habit, stats, err := h.getHabitStatsUsecase.Execute(r.Context(), habitID, userID, query)
+	if err != nil {
+		switch err {
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "habit not found"}, h.logger)
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		default:
+			h.logger.Printf("Error getting stats for habit %s: %v", habitID, err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	response := dto.HabitStatsResponseDTO{
+		HabitID:          habit.ID,
+		CurrentStreak:    habit.CurrentStreak,
+		BestStreak:       habit.BestStreak,
+		TotalCompletions: habit.TotalCompletions,
+		CompletionRates:  []dto.CompletionRateDTO{},
+		AverageCount:     stats.AverageCount,
+		LongestGap:       stats.LongestGap,
+		Series:           "weekly",
+		TimeSeries:       []dto.StatsBucketDTO{},
+	}
+	if query.Series != nil {
+		response.Series = *query.Series
+	}
+	if habit.IsMeasurable() {
+		response.TotalValue = &stats.TotalValue
+		response.AverageValue = &stats.AverageValue
+	}
+	if stats.BestWeekday != nil {
+		weekday := strings.ToLower(stats.BestWeekday.String())
+		response.BestWeekday = &weekday
+	}
+	for _, rate := range stats.Rates {
+		response.CompletionRates = append(response.CompletionRates, dto.CompletionRateDTO{
+			Days:      rate.Days,
+			Scheduled: rate.Scheduled,
+			Fulfilled: rate.Fulfilled,
+			Rate:      rate.Rate,
+		})
+	}
+	for _, bucket := range stats.Series {
+		response.TimeSeries = append(response.TimeSeries, dto.StatsBucketDTO{
+			Start:     bucket.Start.Format("2006-01-02"),
+			End:       bucket.End.Format("2006-01-02"),
+			Count:     bucket.Count,
+			Value:     bucket.Value,
+			Scheduled: bucket.Scheduled,
+			Fulfilled: bucket.Fulfilled,
+			Rate:      bucket.Rate,
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"stats": response}, h.logger)
+}
+
+func (h *StatsHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	overview, err := h.getOverviewUsecase.Execute(r.Context(), userID)
+	if err != nil {
+		h.logger.Printf("Error getting overview for user %s: %v", userID, err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	response := dto.OverviewResponseDTO{
+		Date:       overview.Date.Format("2006-01-02"),
+		Today:      toAdherenceDTO(overview.Today),
+		Week:       toAdherenceDTO(overview.Week),
+		Month:      toAdherenceDTO(overview.Month),
+		AtRisk:     []dto.AtRiskHabitDTO{},
+		Categories: []dto.CategoryTotalsDTO{},
+	}
+	for _, due := range overview.AtRisk {
+		response.AtRisk = append(response.AtRisk, dto.AtRiskHabitDTO{
+			HabitID:       due.Habit.ID,
+			Name:          due.Habit.Name,
+			Color:         due.Habit.Color,
+			CurrentStreak: due.Habit.CurrentStreak,
+			Count:         due.Count(),
+			TargetCount:   due.TargetCount(),
+			Progress:      due.Progress(),
+		})
+	}
+	for _, totals := range overview.Categories {
+		response.Categories = append(response.Categories, dto.CategoryTotalsDTO{
+			Category:         totals.Category,
+			Habits:           totals.Habits,
+			ActiveHabits:     totals.ActiveHabits,
+			TotalCompletions: totals.TotalCompletions,
+			CurrentStreaks:   totals.CurrentStreaks,
+			Week:             toAdherenceDTO(totals.Week),
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"overview": response}, h.logger)
+}
+
+func (h *StatsHandler) GetCorrelations(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	query := dto.CorrelationsQueryDTO{}
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		query.From = &from
+	}
+
+	if to := r.URL.Query().Get("to"); to != "" {
+		query.To = &to
+	}
+
+	if minSamplesStr := r.URL.Query().Get("min_samples"); minSamplesStr != "" {
+		if minSamples, err := strconv.Atoi(minSamplesStr); err == nil {
+			query.MinSamples = &minSamples
+		}
+	}
+
+	if err := h.v.Struct(&query); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return
+	}
+
+	report, err := h.getCorrelationsUsecase.Execute(r.Context(), userID, query)
+	if err != nil {
+		switch err {
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "from must not be after to, and the range can't exceed a year"}, h.logger)
+		default:
+			h.logger.Printf("Error getting correlations for user %s: %v", userID, err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	response := dto.CorrelationsResponseDTO{
+		From:         report.From.Format("2006-01-02"),
+		To:           report.To.Format("2006-01-02"),
+		MinSamples:   report.MinSamples,
+		Correlations: []dto.HabitCorrelationDTO{},
+	}
+	for _, correlation := range report.Correlations {
+		response.Correlations = append(response.Correlations, dto.HabitCorrelationDTO{
+			HabitA:        toHabitSummaryDTO(correlation.HabitA),
+			HabitB:        toHabitSummaryDTO(correlation.HabitB),
+			Samples:       correlation.Samples,
+			FulfilledA:    correlation.FulfilledA,
+			FulfilledB:    correlation.FulfilledB,
+			FulfilledBoth: correlation.FulfilledBoth,
+			ConfidenceAB:  correlation.ConfidenceAB,
+			ConfidenceBA:  correlation.ConfidenceBA,
+			Lift:          correlation.Lift,
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"correlations": response}, h.logger)
+}
+
+func toHabitSummaryDTO(habit *entity.Habit) dto.HabitSummaryDTO {
+	return dto.HabitSummaryDTO{
+		ID:    habit.ID,
+		Name:  habit.Name,
+		Color: habit.Color,
+	}
+}
+
+func toAdherenceDTO(adherence entity.Adherence) dto.AdherenceDTO {
+	return dto.AdherenceDTO{
+		Scheduled: adherence.Scheduled,
+		Fulfilled: adherence.Fulfilled,
+		Rate:      adherence.Rate(),
+	}
+}
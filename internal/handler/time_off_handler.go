@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	timeOffUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/timeoff"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type TimeOffHandler struct {
+	createSkipUsecase  *timeOffUsecase.CreateSkipUsecase
+	deleteSkipUsecase  *timeOffUsecase.DeleteSkipUsecase
+	getPausesUsecase   *timeOffUsecase.GetPausesUsecase
+	createPauseUsecase *timeOffUsecase.CreatePauseUsecase
+	deletePauseUsecase *timeOffUsecase.DeletePauseUsecase
+	logger             *log.Logger
+	v                  *validator.Validate
+}
+
+func NewTimeOffHandler(
+	createSkipUsecase *timeOffUsecase.CreateSkipUsecase,
+	deleteSkipUsecase *timeOffUsecase.DeleteSkipUsecase,
+	getPausesUsecase *timeOffUsecase.GetPausesUsecase,
+	createPauseUsecase *timeOffUsecase.CreatePauseUsecase,
+	deletePauseUsecase *timeOffUsecase.DeletePauseUsecase,
+	logger *log.Logger,
+	v *validator.Validate,
+) *TimeOffHandler {
+	return &TimeOffHandler{
+		createSkipUsecase:  createSkipUsecase,
+		deleteSkipUsecase:  deleteSkipUsecase,
+		getPausesUsecase:   getPausesUsecase,
+		createPauseUsecase: createPauseUsecase,
+		deletePauseUsecase: deletePauseUsecase,
+		logger:             logger,
+		v:                  v,
+	}
+}
+
+func (h *TimeOffHandler) CreateSkip(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	var req dto.CreateSkipDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Failed to decode request: %v", err)
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_request_format"}, h.logger)
+		return
+	}
+
+	if err := h.v.Struct(&req); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return
+	}
+
+	skip, err := h.createSkipUsecase.Execute(r.Context(), habitID, userID, req)
+	if err != nil {
+		switch err {
+		case apperrors.ErrAlreadyExists:
+			utils.WriteJSON(w, http.StatusConflict, utils.APIResponse{"error": "habit is already skipped on this date"}, h.logger)
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_input"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "habit not found"}, h.logger)
+		default:
+			h.logger.Printf("Error creating skip: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.logger.Printf("Skip created successfully. SkipID: %s, HabitID: %s, UserID: %s", skip.ID, habitID, userID)
+	utils.WriteJSON(w, http.StatusCreated, utils.APIResponse{"skip": toSkipResponseDTO(skip)}, h.logger)
+}
+
+func (h *TimeOffHandler) DeleteSkip(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	skipID := r.PathValue("skipID")
+
+	err = h.deleteSkipUsecase.Execute(r.Context(), skipID, userID)
+	if err != nil {
+		switch err {
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "skip not found"}, h.logger)
+		default:
+			h.logger.Printf("Error deleting skip: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.logger.Printf("Skip deleted successfully. SkipID: %s, UserID: %s", skipID, userID)
+	utils.WriteJSON(w, http.StatusNoContent, nil, h.logger)
+}
+
+func (h *TimeOffHandler) GetPauses(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	pauses, err := h.getPausesUsecase.Execute(r.Context(), userID)
+	if err != nil {
+		h.logger.Printf("Error getting pauses for user %s: %v", userID, err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"pauses": toPauseResponseDTOs(pauses)}, h.logger)
+}
+
+func (h *TimeOffHandler) CreatePause(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	var req dto.CreatePauseDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Failed to decode request: %v", err)
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_request_format"}, h.logger)
+		return
+	}
+
+	if err := h.v.Struct(&req); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return
+	}
+
+	pause, err := h.createPauseUsecase.Execute(r.Context(), userID, req)
+	if err != nil {
+		switch err {
+		case apperrors.ErrAlreadyExists:
+			utils.WriteJSON(w, http.StatusConflict, utils.APIResponse{"error": "pause overlaps an existing pause"}, h.logger)
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_input"}, h.logger)
+		default:
+			h.logger.Printf("Error creating pause: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.logger.Printf("Pause created successfully. PauseID: %s, UserID: %s", pause.ID, userID)
+	utils.WriteJSON(w, http.StatusCreated, utils.APIResponse{"pause": toPauseResponseDTO(pause)}, h.logger)
+}
+
+func (h *TimeOffHandler) DeletePause(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	pauseID := r.PathValue("pauseID")
+
+	err = h.deletePauseUsecase.Execute(r.Context(), pauseID, userID)
+	if err != nil {
+		switch err {
+		case apperrors.ErrForbidden:
+			utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "pause not found"}, h.logger)
+		default:
+			h.logger.Printf("Error deleting pause: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.logger.Printf("Pause deleted successfully. PauseID: %s, UserID: %s", pauseID, userID)
+	utils.WriteJSON(w, http.StatusNoContent, nil, h.logger)
+}
+
+func toSkipResponseDTO(skip *entity.HabitSkip) dto.SkipResponseDTO {
+	return dto.SkipResponseDTO{
+		ID:        skip.ID,
+		HabitID:   skip.HabitID,
+		SkipDate:  skip.SkipDate,
+		Reason:    skip.Reason,
+		CreatedAt: skip.CreatedAt,
+	}
+}
+
+func toSkipResponseDTOs(skips []*entity.HabitSkip) []dto.SkipResponseDTO {
+	responses := []dto.SkipResponseDTO{}
+	for _, skip := range skips {
+		responses = append(responses, toSkipResponseDTO(skip))
+	}
+	return responses
+}
+
+func toPauseResponseDTO(pause *entity.Pause) dto.PauseResponseDTO {
+	return dto.PauseResponseDTO{
+		ID:        pause.ID,
+		StartDate: pause.StartDate,
+		EndDate:   pause.EndDate,
+		Reason:    pause.Reason,
+		CreatedAt: pause.CreatedAt,
+	}
+}
+
+func toPauseResponseDTOs(pauses []*entity.Pause) []dto.PauseResponseDTO {
+	responses := []dto.PauseResponseDTO{}
+	for _, pause := range pauses {
+		responses = append(responses, toPauseResponseDTO(pause))
+	}
+	return responses
+}
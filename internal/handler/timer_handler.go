@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	timerUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/timer"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type TimerHandler struct {
+	getTimerUsecase   *timerUsecase.GetTimerUsecase
+	startTimerUsecase *timerUsecase.StartTimerUsecase
+	pauseTimerUsecase *timerUsecase.PauseTimerUsecase
+	stopTimerUsecase  *timerUsecase.StopTimerUsecase
+	logger            *log.Logger
+}
+
+func NewTimerHandler(
+	getTimerUsecase *timerUsecase.GetTimerUsecase,
+	startTimerUsecase *timerUsecase.StartTimerUsecase,
+	pauseTimerUsecase *timerUsecase.PauseTimerUsecase,
+	stopTimerUsecase *timerUsecase.StopTimerUsecase,
+	logger *log.Logger,
+) *TimerHandler {
+	return &TimerHandler{
+		getTimerUsecase:   getTimerUsecase,
+		startTimerUsecase: startTimerUsecase,
+		pauseTimerUsecase: pauseTimerUsecase,
+		stopTimerUsecase:  stopTimerUsecase,
+		logger:            logger,
+	}
+}
+
+func (h *TimerHandler) GetTimer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	session, err := h.getTimerUsecase.Execute(r.Context(), habitID, userID)
+	if err != nil {
+		h.writeTimerError(w, err, "no timer is running for this habit", "getting timer")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"timer": toTimerSessionResponseDTO(session, time.Now())}, h.logger)
+}
+
+func (h *TimerHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	session, err := h.startTimerUsecase.Execute(r.Context(), habitID, userID)
+	if err != nil {
+		h.writeTimerError(w, err, "habit not found", "starting timer")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"timer": toTimerSessionResponseDTO(session, time.Now())}, h.logger)
+}
+
+func (h *TimerHandler) PauseTimer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	session, err := h.pauseTimerUsecase.Execute(r.Context(), habitID, userID)
+	if err != nil {
+		h.writeTimerError(w, err, "no timer is running for this habit", "pausing timer")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"timer": toTimerSessionResponseDTO(session, time.Now())}, h.logger)
+}
+
+func (h *TimerHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.logger.Printf("Failed to get user ID from context: %v", err)
+		utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "unauthorized"}, h.logger)
+		return
+	}
+
+	habitID := r.PathValue("habitID")
+
+	session, completion, err := h.stopTimerUsecase.Execute(r.Context(), habitID, userID)
+	if err != nil {
+		h.writeTimerError(w, err, "no timer is running for this habit", "stopping timer")
+		return
+	}
+
+	response := utils.APIResponse{"timer": toTimerSessionResponseDTO(session, time.Now()), "completion": nil}
+	if completion != nil {
+		response["completion"] = toCompletionResponseDTO(completion)
+	}
+
+	h.logger.Printf("Timer stopped. SessionID: %s, HabitID: %s, Minutes: %d", session.ID, habitID, session.Minutes())
+	utils.WriteJSON(w, http.StatusOK, response, h.logger)
+}
+
+func (h *TimerHandler) writeTimerError(w http.ResponseWriter, err error, notFound, action string) {
+	switch err {
+	case apperrors.ErrAlreadyExists:
+		utils.WriteJSON(w, http.StatusConflict, utils.APIResponse{"error": "timer is already running for this habit"}, h.logger)
+	case apperrors.ErrForbidden:
+		utils.WriteJSON(w, http.StatusForbidden, utils.APIResponse{"error": "forbidden"}, h.logger)
+	case apperrors.ErrInvalidInput:
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "timers are only available for active duration habits"}, h.logger)
+	case apperrors.ErrNotFound:
+		utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": notFound}, h.logger)
+	default:
+		h.logger.Printf("Error %s: %v", action, err)
+		utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+	}
+}
+
+func toTimerSessionResponseDTO(session *entity.TimerSession, now time.Time) dto.TimerSessionResponseDTO {
+	return dto.TimerSessionResponseDTO{
+		ID:             session.ID,
+		HabitID:        session.HabitID,
+		Status:         session.Status,
+		StartedAt:      session.StartedAt,
+		ResumedAt:      session.ResumedAt,
+		ElapsedSeconds: int(session.Elapsed(now).Seconds()),
+		StoppedAt:      session.StoppedAt,
+		CompletionID:   session.CompletionID,
+	}
+}
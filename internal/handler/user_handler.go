@@ -1,20 +1,26 @@
 package handler
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 
+	"github.com/go-playground/validator/v10"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
 	userUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/user"
 	"github.com/uygardeniz/habit-tracker/internal/utils"
 )
 
 type UserHandler struct {
-	logger       *log.Logger
-	getMeUsecase *userUsecase.GetMeUsecase
+	logger          *log.Logger
+	getMeUsecase    *userUsecase.GetMeUsecase
+	updateMeUsecase *userUsecase.UpdateMeUsecase
+	v               *validator.Validate
 }
 
-func NewUserHandler(logger *log.Logger, getMeUsecase *userUsecase.GetMeUsecase) *UserHandler {
-	return &UserHandler{logger: logger, getMeUsecase: getMeUsecase}
+func NewUserHandler(logger *log.Logger, getMeUsecase *userUsecase.GetMeUsecase, updateMeUsecase *userUsecase.UpdateMeUsecase, v *validator.Validate) *UserHandler {
+	return &UserHandler{logger: logger, getMeUsecase: getMeUsecase, updateMeUsecase: updateMeUsecase, v: v}
 }
 
 func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
@@ -26,3 +32,34 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"user": user}, h.logger)
 }
+
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateMeDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Printf("Failed to decode request: %v", err)
+		utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_request_format"}, h.logger)
+		return
+	}
+
+	if err := h.v.Struct(&req); err != nil {
+		utils.WriteValidationErrorResponse(w, http.StatusBadRequest, utils.APIResponse{"error": "validation_failed"}, err, h.logger)
+		return
+	}
+
+	user, err := h.updateMeUsecase.Execute(r.Context(), req)
+	if err != nil {
+		switch err {
+		case apperrors.ErrInvalidInput:
+			utils.WriteJSON(w, http.StatusBadRequest, utils.APIResponse{"error": "invalid_input"}, h.logger)
+		case apperrors.ErrNotFound:
+			utils.WriteJSON(w, http.StatusNotFound, utils.APIResponse{"error": "user not found"}, h.logger)
+		default:
+			h.logger.Printf("Error updating user: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, h.logger)
+		}
+		return
+	}
+
+	h.logger.Printf("User updated successfully. UserID: %s", user.ID)
+	utils.WriteJSON(w, http.StatusOK, utils.APIResponse{"user": user}, h.logger)
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// LogMailer writes emails to the log instead of sending them, for development
+type LogMailer struct {
+	logger *log.Logger
+}
+
+func NewLogMailer(logger *log.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	m.logger.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import "fmt"
+
+// VerificationMessage asks a new user to confirm their email address
+func VerificationMessage(to, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Welcome to Habit Tracker!\n\nOpen this link to verify your email address:\n%s\n\nThe link expires in 24 hours. If you didn't sign up, you can ignore this email.\n",
+			link,
+		),
+	}
+}
+
+// AccountExistsMessage tells someone signing up with an email address that already has
+// an account how to get into it, without revealing to the sign up form that it exists
+func AccountExistsMessage(to, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "You already have an account",
+		Body: fmt.Sprintf(
+			"Someone tried to sign up to Habit Tracker with this email address, which already has an account.\n\nIf it was you, log in or reset your password here:\n%s\n\nIf it wasn't, you can ignore this email.\n",
+			link,
+		),
+	}
+}
+
+// PasswordResetMessage sends a link for choosing a new password
+func PasswordResetMessage(to, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Open this link to choose a new password:\n%s\n\nThe link expires in 1 hour. If you didn't ask to reset your password, you can ignore this email.\n",
+			link,
+		),
+	}
+}
+
+// MagicLinkMessage sends a link that logs the user in without a password
+func MagicLinkMessage(to, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Open this link to log in to Habit Tracker:\n%s\n\nThe link expires in 15 minutes and works once. If you didn't ask to log in, you can ignore this email.\n",
+			link,
+		),
+	}
+}
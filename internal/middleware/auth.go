@@ -8,20 +8,25 @@ import (
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	sessionUsecase "github.com/uygardeniz/habit-tracker/internal/usecases/session"
 	"github.com/uygardeniz/habit-tracker/internal/utils"
 )
 
 type AuthMiddleware struct {
-	logger *log.Logger
+	logger                 *log.Logger
+	validateSessionUsecase *sessionUsecase.ValidateSessionUsecase
 }
 
-func NewAuthMiddleware(logger *log.Logger) *AuthMiddleware {
+func NewAuthMiddleware(logger *log.Logger, validateSessionUsecase *sessionUsecase.ValidateSessionUsecase) *AuthMiddleware {
 	return &AuthMiddleware{
-		logger: logger,
+		logger:                 logger,
+		validateSessionUsecase: validateSessionUsecase,
 	}
 }
 
-// RequireAuth validates JWT token and sets user context
+// RequireAuth validates JWT token and sets user context. Tokens of sessions that were
+// revoked are rejected.
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var tokenString string
@@ -69,7 +74,26 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			return
 		}
 
+		sessionID, ok := claims["sid"].(string)
+		if !ok {
+			m.logger.Printf("Missing or invalid session ID in token claims")
+			utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "invalid_token_claims"}, m.logger)
+			return
+		}
+
+		if err := m.validateSessionUsecase.Execute(r.Context(), sessionID, userID); err != nil {
+			if err == apperrors.ErrUnauthorized {
+				m.logger.Printf("Session is no longer active. SessionID: %s", sessionID)
+				utils.WriteJSON(w, http.StatusUnauthorized, utils.APIResponse{"error": "session_revoked"}, m.logger)
+				return
+			}
+			m.logger.Printf("Failed to validate session: %v", err)
+			utils.WriteJSON(w, http.StatusInternalServerError, utils.APIResponse{"error": "internal_server_error"}, m.logger)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		ctx = context.WithValue(ctx, SessionIDKey, sessionID)
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
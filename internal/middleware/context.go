@@ -8,7 +8,8 @@ import (
 type contextKey string
 
 const (
-	UserIDKey contextKey = "user_id"
+	UserIDKey    contextKey = "user_id"
+	SessionIDKey contextKey = "session_id"
 )
 
 // GetUserIDFromContext safely extracts user ID from request context
@@ -20,6 +21,16 @@ func GetUserIDFromContext(ctx context.Context) (string, error) {
 	return userID, nil
 }
 
+// GetSessionIDFromContext safely extracts the ID of the session the request's access
+// token was issued for
+func GetSessionIDFromContext(ctx context.Context) (string, error) {
+	sessionID, ok := ctx.Value(SessionIDKey).(string)
+	if !ok {
+		return "", errors.New("session ID not found in context")
+	}
+	return sessionID, nil
+}
+
 func IsAuthenticated(ctx context.Context) bool {
 	_, err := GetUserIDFromContext(ctx)
 	return err == nil
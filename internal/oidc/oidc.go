@@ -0,0 +1,286 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+	"golang.org/x/oauth2"
+)
+
+// DefaultScopes are requested when a provider's config doesn't list any
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// Config describes an OpenID Connect provider. Its endpoints are discovered from Issuer.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// Whether the issuer's email_verified claim is trusted. Profiles of other issuers are
+	// never EmailVerified, so their emails can't claim existing accounts.
+	TrustEmail bool
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// claims are the standard OIDC claims the ID token and the userinfo endpoint share
+type claims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	Nonce         string `json:"nonce"`
+}
+
+// OIDCProvider logs users in with any OpenID Connect issuer. The issuer's discovery
+// document is fetched on first use and kept for the life of the provider.
+type OIDCProvider struct {
+	config    Config
+	client    *http.Client
+	mu        sync.Mutex
+	discovery *discoveryDocument
+}
+
+// NewOIDCProvider creates a provider that talks to the issuer with client, or with a
+// client with a short timeout when nil
+func NewOIDCProvider(config Config, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultScopes
+	}
+	return &OIDCProvider{config: config, client: client}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return p.oauthConfig(discovery).AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	), nil
+}
+
+// Exchange redeems the code at the token endpoint and reads the profile from the ID
+// token, completed by the userinfo endpoint when the issuer has one
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*Profile, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := p.oauthConfig(discovery).Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	idClaims, err := p.parseIDToken(rawIDToken, discovery, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if discovery.UserinfoEndpoint != "" {
+		userinfo, err := p.fetchUserinfo(ctx, discovery, token)
+		if err != nil {
+			return nil, err
+		}
+		if userinfo.Subject != idClaims.Subject {
+			return nil, errors.New("userinfo subject does not match the id_token")
+		}
+		idClaims.merge(userinfo)
+	}
+
+	return &Profile{
+		Subject:       idClaims.Subject,
+		Email:         idClaims.Email,
+		EmailVerified: p.config.TrustEmail && isTrue(idClaims.EmailVerified),
+		Name:          idClaims.Name,
+		Picture:       idClaims.Picture,
+	}, nil
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	issuer := strings.TrimSuffix(p.config.Issuer, "/")
+	if !utils.IsSecureURL(issuer) {
+		return nil, fmt.Errorf("oidc issuer of %s is not served over https", p.config.Name)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var discovery discoveryDocument
+	if err := p.getJSON(req, &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery for %s failed: %w", p.config.Name, err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc discovery for %s returned issuer %q", p.config.Name, discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return nil, fmt.Errorf("oidc discovery for %s is missing endpoints", p.config.Name)
+	}
+	// The ID token is trusted for coming from these endpoints rather than for its
+	// signature, so they have to be reached over TLS
+	if !utils.IsSecureURL(discovery.TokenEndpoint) || (discovery.UserinfoEndpoint != "" && !utils.IsSecureURL(discovery.UserinfoEndpoint)) {
+		return nil, fmt.Errorf("oidc discovery for %s returned endpoints not served over https", p.config.Name)
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) oauthConfig(discovery *discoveryDocument) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Scopes:       p.config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}
+}
+
+// parseIDToken checks the ID token was issued by the issuer to this client for the login
+// with the nonce, and hasn't expired. Its signature isn't checked: the token came
+// straight from the token endpoint over TLS, which OIDC allows relying on in place of
+// the signature.
+func (p *OIDCProvider) parseIDToken(rawIDToken string, discovery *discoveryDocument, nonce string) (*claims, error) {
+	mapClaims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(rawIDToken, mapClaims); err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	issuer, err := mapClaims.GetIssuer()
+	if err != nil || strings.TrimSuffix(issuer, "/") != strings.TrimSuffix(discovery.Issuer, "/") {
+		return nil, errors.New("id_token was issued by another issuer")
+	}
+
+	audience, err := mapClaims.GetAudience()
+	if err != nil || !slices.Contains(audience, p.config.ClientID) {
+		return nil, errors.New("id_token was issued to another client")
+	}
+
+	expiresAt, err := mapClaims.GetExpirationTime()
+	if err != nil || expiresAt == nil || !time.Now().Before(expiresAt.Time) {
+		return nil, errors.New("id_token has expired")
+	}
+
+	encoded, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	var idClaims claims
+	if err := json.Unmarshal(encoded, &idClaims); err != nil {
+		return nil, err
+	}
+
+	if idClaims.Subject == "" {
+		return nil, errors.New("id_token has no subject")
+	}
+
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(idClaims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("id_token nonce does not match")
+	}
+
+	return &idClaims, nil
+}
+
+func (p *OIDCProvider) fetchUserinfo(ctx context.Context, discovery *discoveryDocument, token *oauth2.Token) (*claims, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.UserinfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	token.SetAuthHeader(req)
+
+	var userinfo claims
+	if err := p.getJSON(req, &userinfo); err != nil {
+		return nil, fmt.Errorf("failed getting user info: %w", err)
+	}
+
+	return &userinfo, nil
+}
+
+func (p *OIDCProvider) getJSON(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+
+	response, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", response.StatusCode, req.URL)
+	}
+
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// merge takes the profile claims other has over those of c
+func (c *claims) merge(other *claims) {
+	if other.Email != "" {
+		c.Email = other.Email
+		c.EmailVerified = other.EmailVerified
+	}
+	if other.Name != "" {
+		c.Name = other.Name
+	}
+	if other.Picture != "" {
+		c.Picture = other.Picture
+	}
+}
+
+// isTrue reads a boolean claim, which some providers send as a string
+func isTrue(claim any) bool {
+	switch value := claim.(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,312 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+const (
+	testClientID = "habit-tracker"
+	testCode     = "authorization-code"
+	testNonce    = "login-nonce"
+	testSubject  = "user-123"
+)
+
+// mockIssuer is an OpenID Connect provider serving discovery, token and userinfo
+// endpoints. Tests adjust its responses through the fields before exchanging a code.
+type mockIssuer struct {
+	server *httptest.Server
+	// Issuer put in the discovery document, the server's URL when empty
+	discoveryIssuer string
+	// Base URL of the token endpoint in the discovery document, the server's URL when
+	// empty
+	tokenEndpointBase string
+	// Claims of the ID token, on top of valid iss, aud, exp, sub and nonce
+	idClaims jwt.MapClaims
+	// Whether the token response leaves the ID token out
+	noIDToken bool
+	// Userinfo response, no userinfo endpoint when nil
+	userinfo map[string]any
+	// Status of the token endpoint, 200 when zero
+	tokenStatus int
+	// Verifier the last token request came with
+	verifier string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	issuer := &mockIssuer{idClaims: jwt.MapClaims{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		document := map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+		}
+		if issuer.discoveryIssuer != "" {
+			document["issuer"] = issuer.discoveryIssuer
+		}
+		if issuer.tokenEndpointBase != "" {
+			document["token_endpoint"] = issuer.tokenEndpointBase + "/token"
+		}
+		if issuer.userinfo != nil {
+			document["userinfo_endpoint"] = issuer.server.URL + "/userinfo"
+		}
+		json.NewEncoder(w).Encode(document)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if issuer.tokenStatus != 0 {
+			w.WriteHeader(issuer.tokenStatus)
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != testCode {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		issuer.verifier = r.PostForm.Get("code_verifier")
+
+		response := map[string]any{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600}
+		if !issuer.noIDToken {
+			response["id_token"] = issuer.idToken(t)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(issuer.userinfo)
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (m *mockIssuer) idToken(t *testing.T) string {
+	claims := jwt.MapClaims{
+		"iss":   m.server.URL,
+		"aud":   testClientID,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"sub":   testSubject,
+		"nonce": testNonce,
+	}
+	for name, value := range m.idClaims {
+		claims[name] = value
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("issuer-key"))
+	require.NoError(t, err)
+	return token
+}
+
+func (m *mockIssuer) provider(trustEmail bool) *OIDCProvider {
+	return NewOIDCProvider(Config{
+		Name:         "mock",
+		Issuer:       m.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/api/auth/mock/callback",
+		TrustEmail:   trustEmail,
+	}, m.server.Client())
+}
+
+func TestOIDCProviderAuthCodeURL(t *testing.T) {
+	issuer := newMockIssuer(t)
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := issuer.provider(false).AuthCodeURL(context.Background(), "login-state", testNonce, verifier)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, issuer.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+
+	query := parsed.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, testClientID, query.Get("client_id"))
+	assert.Equal(t, "login-state", query.Get("state"))
+	assert.Equal(t, testNonce, query.Get("nonce"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, oauth2.S256ChallengeFromVerifier(verifier), query.Get("code_challenge"))
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustEmail bool
+		idClaims   jwt.MapClaims
+		userinfo   map[string]any
+		want       *Profile
+	}{
+		{
+			name:     "profile from the id token",
+			idClaims: jwt.MapClaims{"email": "ada@example.com", "name": "Ada"},
+			want:     &Profile{Subject: testSubject, Email: "ada@example.com", Name: "Ada"},
+		},
+		{
+			name:     "userinfo completes the id token",
+			idClaims: jwt.MapClaims{"email": "ada@example.com"},
+			userinfo: map[string]any{"sub": testSubject, "name": "Ada Lovelace", "picture": "https://example.com/ada.png"},
+			want:     &Profile{Subject: testSubject, Email: "ada@example.com", Name: "Ada Lovelace", Picture: "https://example.com/ada.png"},
+		},
+		{
+			name:       "verified email of a trusted issuer",
+			trustEmail: true,
+			idClaims:   jwt.MapClaims{"email": "ada@example.com", "email_verified": true},
+			want:       &Profile{Subject: testSubject, Email: "ada@example.com", EmailVerified: true},
+		},
+		{
+			name:       "verified email sent as a string",
+			trustEmail: true,
+			userinfo:   map[string]any{"sub": testSubject, "email": "ada@example.com", "email_verified": "true"},
+			want:       &Profile{Subject: testSubject, Email: "ada@example.com", EmailVerified: true},
+		},
+		{
+			name:     "verified email of an untrusted issuer",
+			idClaims: jwt.MapClaims{"email": "ada@example.com", "email_verified": true},
+			want:     &Profile{Subject: testSubject, Email: "ada@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newMockIssuer(t)
+			issuer.idClaims = tt.idClaims
+			issuer.userinfo = tt.userinfo
+			verifier := oauth2.GenerateVerifier()
+
+			profile, err := issuer.provider(tt.trustEmail).Exchange(context.Background(), testCode, verifier, testNonce)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, profile)
+			assert.Equal(t, verifier, issuer.verifier)
+		})
+	}
+}
+
+func TestOIDCProviderExchangeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(issuer *mockIssuer)
+		code    string
+		nonce   string
+		wantErr string
+	}{
+		{
+			name:    "discovery of another issuer",
+			setup:   func(issuer *mockIssuer) { issuer.discoveryIssuer = "https://evil.example.com" },
+			wantErr: "returned issuer",
+		},
+		{
+			name:    "token endpoint without TLS",
+			setup:   func(issuer *mockIssuer) { issuer.tokenEndpointBase = "http://idp.example.com" },
+			wantErr: "not served over https",
+		},
+		{
+			name:    "rejected code",
+			code:    "wrong-code",
+			wantErr: "code exchange failed",
+		},
+		{
+			name:    "token endpoint error",
+			setup:   func(issuer *mockIssuer) { issuer.tokenStatus = http.StatusInternalServerError },
+			wantErr: "code exchange failed",
+		},
+		{
+			name:    "missing id token",
+			setup:   func(issuer *mockIssuer) { issuer.noIDToken = true },
+			wantErr: "no id_token",
+		},
+		{
+			name:    "id token of another issuer",
+			setup:   func(issuer *mockIssuer) { issuer.idClaims["iss"] = "https://evil.example.com" },
+			wantErr: "another issuer",
+		},
+		{
+			name:    "id token of another client",
+			setup:   func(issuer *mockIssuer) { issuer.idClaims["aud"] = "other-client" },
+			wantErr: "another client",
+		},
+		{
+			name:    "expired id token",
+			setup:   func(issuer *mockIssuer) { issuer.idClaims["exp"] = time.Now().Add(-time.Minute).Unix() },
+			wantErr: "expired",
+		},
+		{
+			name:    "id token without subject",
+			setup:   func(issuer *mockIssuer) { issuer.idClaims["sub"] = "" },
+			wantErr: "no subject",
+		},
+		{
+			name:    "id token of another login",
+			nonce:   "other-nonce",
+			wantErr: "nonce does not match",
+		},
+		{
+			name:    "id token without nonce",
+			setup:   func(issuer *mockIssuer) { issuer.idClaims["nonce"] = "" },
+			wantErr: "nonce does not match",
+		},
+		{
+			name:    "userinfo of another user",
+			setup:   func(issuer *mockIssuer) { issuer.userinfo = map[string]any{"sub": "someone-else"} },
+			wantErr: "userinfo subject does not match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newMockIssuer(t)
+			if tt.setup != nil {
+				tt.setup(issuer)
+			}
+			code := testCode
+			if tt.code != "" {
+				code = tt.code
+			}
+			nonce := testNonce
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			profile, err := issuer.provider(true).Exchange(context.Background(), code, oauth2.GenerateVerifier(), nonce)
+			require.ErrorContains(t, err, tt.wantErr)
+			assert.Nil(t, profile)
+		})
+	}
+}
+
+func TestOIDCProviderIssuerWithoutTLS(t *testing.T) {
+	provider := NewOIDCProvider(Config{Name: "acme", Issuer: "http://idp.example.com", ClientID: testClientID}, nil)
+
+	_, err := provider.AuthCodeURL(context.Background(), "login-state", testNonce, oauth2.GenerateVerifier())
+	require.ErrorContains(t, err, "not served over https")
+}
+
+func TestRegistry(t *testing.T) {
+	issuer := newMockIssuer(t)
+	registry := NewRegistry(issuer.provider(false), NewOIDCProvider(Config{Name: "acme", Issuer: issuer.server.URL}, nil))
+
+	assert.Equal(t, []string{"acme", "mock"}, registry.Names())
+
+	provider, err := registry.Get("mock")
+	require.NoError(t, err)
+	assert.Equal(t, "mock", provider.Name())
+
+	_, err = registry.Get("unknown")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
@@ -0,0 +1,64 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+// Profile is what an identity provider tells about the user who logged in
+type Profile struct {
+	// The user's stable ID at the provider
+	Subject string
+	Email   string
+	// Whether the email is verified by a provider trusted to verify emails
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Provider is an identity provider users can log in with through the authorization code
+// flow with PKCE. The nonce of a login is sent with the authorization request and must
+// come back in the ID token, tying the token to that login.
+type Provider interface {
+	// Name identifies the provider in URLs and in stored identities
+	Name() string
+	// AuthCodeURL returns the URL to send the browser to for logging in
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Exchange redeems the code the browser came back with for the user's profile
+	Exchange(ctx context.Context, code, verifier, nonce string) (*Profile, error)
+}
+
+// Registry holds the identity providers that are configured
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	registry := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+	return registry
+}
+
+// Get returns the provider with the given name, or ErrUnknownProvider
+func (r *Registry) Get(name string) (Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// Names returns the names of the configured providers in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
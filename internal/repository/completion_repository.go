@@ -12,13 +12,19 @@ import (
 )
 
 type CompletionRepository interface {
-	Create(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit) (*entity.HabitCompletion, error)
+	Create(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) (*entity.HabitCompletion, error)
 	FindByID(ctx context.Context, id string) (*entity.HabitCompletion, error)
 	FindByUserID(ctx context.Context, userID string, habitID *string, startDate, endDate *time.Time, limit, offset int) ([]*entity.HabitCompletion, error)
 	FindByHabitID(ctx context.Context, habitID string, startDate, endDate *time.Time, limit, offset int) ([]*entity.HabitCompletion, error)
 	FindByHabitIDAndDate(ctx context.Context, habitID string, date time.Time) (*entity.HabitCompletion, error)
-	Update(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit) error
-	Delete(ctx context.Context, id string, habit *entity.Habit) error
+	FindAllByHabitID(ctx context.Context, habitID string) ([]*entity.HabitCompletion, error)
+	FindAllByUserIDInRange(ctx context.Context, userID string, startDate, endDate time.Time) ([]*entity.HabitCompletion, error)
+	AggregateDailyByUserID(ctx context.Context, userID string, habitID *string, startDate, endDate time.Time) ([]*entity.HeatmapDay, error)
+	Update(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) error
+	Delete(ctx context.Context, id string, habit *entity.Habit, today time.Time) error
+	RecalculateHabitStats(ctx context.Context, habit *entity.Habit, today time.Time) error
+	Increment(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) (*entity.HabitCompletion, error)
+	Decrement(ctx context.Context, habit *entity.Habit, date time.Time, amount int, value *float64, today time.Time) (*entity.HabitCompletion, error)
 	CountByUserID(ctx context.Context, userID string, habitID *string, startDate, endDate *time.Time) (int, error)
 }
 
@@ -30,112 +36,288 @@ func NewPostgresCompletionRepository(db *sql.DB) CompletionRepository {
 	return &PostgresCompletionRepository{db: db}
 }
 
-func (r *PostgresCompletionRepository) Create(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit) (*entity.HabitCompletion, error) {
+const completionColumns = `id, habit_id, user_id, completed_at, completion_date, count, value, target_count, target_value, notes, created_at`
+
+func scanCompletion(row rowScanner) (*entity.HabitCompletion, error) {
+	var completion entity.HabitCompletion
+
+	err := row.Scan(
+		&completion.ID, &completion.HabitID, &completion.UserID,
+		&completion.CompletedAt, &completion.CompletionDate,
+		&completion.Count, &completion.Value, &completion.TargetCount, &completion.TargetValue,
+		&completion.Notes, &completion.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &completion, nil
+}
+
+func (r *PostgresCompletionRepository) Create(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) (*entity.HabitCompletion, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return nil, err
+	}
+
 	completionQuery := `
-		INSERT INTO habit_completions (id, habit_id, user_id, completed_at, completion_date, count, notes, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, habit_id, user_id, completed_at, completion_date, count, notes, created_at
+		INSERT INTO habit_completions (` + completionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + completionColumns + `
 	`
 
 	row := tx.QueryRowContext(ctx, completionQuery,
 		completion.ID, completion.HabitID, completion.UserID, completion.CompletedAt,
-		completion.CompletionDate, completion.Count, completion.Notes, completion.CreatedAt,
+		completion.CompletionDate, completion.Count, completion.Value, completion.TargetCount, completion.TargetValue,
+		completion.Notes, completion.CreatedAt,
 	)
 
-	var createdCompletion entity.HabitCompletion
-	err = row.Scan(
-		&createdCompletion.ID, &createdCompletion.HabitID, &createdCompletion.UserID,
-		&createdCompletion.CompletedAt, &createdCompletion.CompletionDate,
-		&createdCompletion.Count, &createdCompletion.Notes, &createdCompletion.CreatedAt,
-	)
+	createdCompletion, err := scanCompletion(row)
 
 	if err != nil {
 		return nil, err
 	}
 
-	habitQuery := `
-		UPDATE habits
-		SET current_streak = $1, best_streak = $2, total_completions = $3, updated_at = $4
-		WHERE id = $5
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return createdCompletion, nil
+}
+
+func (r *PostgresCompletionRepository) Delete(ctx context.Context, id string, habit *entity.Habit, today time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return err
+	}
+
+	deleteQuery := `DELETE FROM habit_completions WHERE id = $1`
+	result, err := tx.ExecContext(ctx, deleteQuery, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Increment atomically adds the completion's count to the habit's completion for that
+// day, creating the completion if it doesn't exist yet
+func (r *PostgresCompletionRepository) Increment(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) (*entity.HabitCompletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return nil, err
+	}
+
+	updatedCompletion, err := incrementCompletion(ctx, tx, completion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return updatedCompletion, nil
+}
+
+// incrementCompletion upserts the completion within the given transaction, adding its
+// count and value to an existing completion of the same habit and day
+func incrementCompletion(ctx context.Context, tx *sql.Tx, completion *entity.HabitCompletion) (*entity.HabitCompletion, error) {
+	upsertQuery := `
+		INSERT INTO habit_completions (` + completionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (habit_id, completion_date) DO UPDATE
+		SET count = habit_completions.count + EXCLUDED.count,
+			value = CASE WHEN EXCLUDED.value IS NULL THEN habit_completions.value ELSE COALESCE(habit_completions.value, 0) + EXCLUDED.value END,
+			completed_at = EXCLUDED.completed_at
+		RETURNING ` + completionColumns + `
 	`
 
-	result, err := tx.ExecContext(ctx, habitQuery, habit.CurrentStreak, habit.BestStreak, habit.TotalCompletions, habit.UpdatedAt, habit.ID)
+	row := tx.QueryRowContext(ctx, upsertQuery,
+		completion.ID, completion.HabitID, completion.UserID, completion.CompletedAt,
+		completion.CompletionDate, completion.Count, completion.Value, completion.TargetCount, completion.TargetValue,
+		completion.Notes, completion.CreatedAt,
+	)
+
+	return scanCompletion(row)
+}
+
+// Decrement atomically subtracts amount, and value for measurable habits, from the habit's
+// completion for the given day. The completion is removed once its count or value drops
+// to zero, in which case nil is returned.
+func (r *PostgresCompletionRepository) Decrement(ctx context.Context, habit *entity.Habit, date time.Time, amount int, value *float64, today time.Time) (*entity.HabitCompletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return nil, err
+	}
+
+	updateQuery := `
+		UPDATE habit_completions
+		SET count = GREATEST(count - $1, 0),
+			value = CASE WHEN $2::numeric IS NULL THEN value ELSE GREATEST(value - $2::numeric, 0) END
+		WHERE habit_id = $3 AND completion_date = $4
+		RETURNING ` + completionColumns + `
+	`
+
+	row := tx.QueryRowContext(ctx, updateQuery, amount, value, habit.ID, date)
+
+	updatedCompletion, err := scanCompletion(row)
 
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
 		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return nil, apperrors.ErrNotFound
+	result := updatedCompletion
+	if updatedCompletion.Count == 0 || (updatedCompletion.Value != nil && *updatedCompletion.Value <= 0) {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM habit_completions WHERE id = $1`, updatedCompletion.ID); err != nil {
+			return nil, err
+		}
+		result = nil
+	}
+
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
-	return &createdCompletion, nil
+	return result, nil
 }
 
-func (r *PostgresCompletionRepository) Delete(ctx context.Context, id string, habit *entity.Habit) error {
+func (r *PostgresCompletionRepository) RecalculateHabitStats(ctx context.Context, habit *entity.Habit, today time.Time) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	deleteQuery := `DELETE FROM habit_completions WHERE id = $1`
-	result, err := tx.ExecContext(ctx, deleteQuery, id)
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return err
+	}
+
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockHabit takes a row lock on the habit so that concurrent completion writes
+// recalculate its counters one after another
+func lockHabit(ctx context.Context, tx *sql.Tx, habitID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM habits WHERE id = $1 FOR UPDATE`, habitID).Scan(&id)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperrors.ErrNotFound
+		}
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return nil
+}
+
+// recalculateHabitStats rebuilds the denormalized streak and completion counters of
+// the habit from its habit_completions rows, days off and schedule history and stores
+// them within the given transaction
+func recalculateHabitStats(ctx context.Context, tx *sql.Tx, habit *entity.Habit, today time.Time) error {
+	completions, err := findAllByHabitID(ctx, tx, habit.ID)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return apperrors.ErrNotFound
+	timeOff, err := findTimeOff(ctx, tx, habit.UserID, &habit.ID, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	histories, err := findScheduleHistories(ctx, tx, habit.UserID, &habit.ID)
+	if err != nil {
+		return err
 	}
+	habit.ScheduleHistory = histories[habit.ID]
+
+	habit.RecalculateStats(completions, timeOff, today)
 
 	habitQuery := `
 		UPDATE habits
-		SET total_completions = $1
-		WHERE id = $2
+		SET current_streak = $1, best_streak = $2, total_completions = $3
+		WHERE id = $4
 	`
 
-	_, err = tx.ExecContext(ctx, habitQuery, habit.TotalCompletions, habit.ID)
+	result, err := tx.ExecContext(ctx, habitQuery, habit.CurrentStreak, habit.BestStreak, habit.TotalCompletions, habit.ID)
 	if err != nil {
 		return err
 	}
 
-	return tx.Commit()
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	return nil
 }
 
 func (r *PostgresCompletionRepository) FindByID(ctx context.Context, id string) (*entity.HabitCompletion, error) {
 	query := `
-		SELECT id, habit_id, user_id, completed_at, completion_date, count, notes, created_at
+		SELECT ` + completionColumns + `
 		FROM habit_completions
 		WHERE id = $1
 	`
 	row := r.db.QueryRowContext(ctx, query, id)
 
-	var completion entity.HabitCompletion
-	err := row.Scan(
-		&completion.ID, &completion.HabitID, &completion.UserID,
-		&completion.CompletedAt, &completion.CompletionDate,
-		&completion.Count, &completion.Notes, &completion.CreatedAt,
-	)
+	completion, err := scanCompletion(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -144,7 +326,7 @@ func (r *PostgresCompletionRepository) FindByID(ctx context.Context, id string)
 		return nil, err
 	}
 
-	return &completion, nil
+	return completion, nil
 }
 
 func (r *PostgresCompletionRepository) FindByUserID(ctx context.Context, userID string, habitID *string, startDate, endDate *time.Time, limit, offset int) ([]*entity.HabitCompletion, error) {
@@ -175,7 +357,7 @@ func (r *PostgresCompletionRepository) FindByUserID(ctx context.Context, userID
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, habit_id, user_id, completed_at, completion_date, count, notes, created_at
+		SELECT `+completionColumns+`
 		FROM habit_completions
 		WHERE %s
 		ORDER BY completion_date DESC, created_at DESC
@@ -192,16 +374,11 @@ func (r *PostgresCompletionRepository) FindByUserID(ctx context.Context, userID
 
 	var completions []*entity.HabitCompletion
 	for rows.Next() {
-		var completion entity.HabitCompletion
-		err := rows.Scan(
-			&completion.ID, &completion.HabitID, &completion.UserID,
-			&completion.CompletedAt, &completion.CompletionDate,
-			&completion.Count, &completion.Notes, &completion.CreatedAt,
-		)
+		completion, err := scanCompletion(rows)
 		if err != nil {
 			return nil, err
 		}
-		completions = append(completions, &completion)
+		completions = append(completions, completion)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -233,7 +410,7 @@ func (r *PostgresCompletionRepository) FindByHabitID(ctx context.Context, habitI
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, habit_id, user_id, completed_at, completion_date, count, notes, created_at
+		SELECT `+completionColumns+`
 		FROM habit_completions
 		WHERE %s
 		ORDER BY completion_date DESC, created_at DESC
@@ -250,16 +427,11 @@ func (r *PostgresCompletionRepository) FindByHabitID(ctx context.Context, habitI
 
 	var completions []*entity.HabitCompletion
 	for rows.Next() {
-		var completion entity.HabitCompletion
-		err := rows.Scan(
-			&completion.ID, &completion.HabitID, &completion.UserID,
-			&completion.CompletedAt, &completion.CompletionDate,
-			&completion.Count, &completion.Notes, &completion.CreatedAt,
-		)
+		completion, err := scanCompletion(rows)
 		if err != nil {
 			return nil, err
 		}
-		completions = append(completions, &completion)
+		completions = append(completions, completion)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -271,18 +443,13 @@ func (r *PostgresCompletionRepository) FindByHabitID(ctx context.Context, habitI
 
 func (r *PostgresCompletionRepository) FindByHabitIDAndDate(ctx context.Context, habitID string, date time.Time) (*entity.HabitCompletion, error) {
 	query := `
-		SELECT id, habit_id, user_id, completed_at, completion_date, count, notes, created_at
+		SELECT ` + completionColumns + `
 		FROM habit_completions
 		WHERE habit_id = $1 AND completion_date = $2
 	`
 	row := r.db.QueryRowContext(ctx, query, habitID, date)
 
-	var completion entity.HabitCompletion
-	err := row.Scan(
-		&completion.ID, &completion.HabitID, &completion.UserID,
-		&completion.CompletedAt, &completion.CompletionDate,
-		&completion.Count, &completion.Notes, &completion.CreatedAt,
-	)
+	completion, err := scanCompletion(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -291,34 +458,145 @@ func (r *PostgresCompletionRepository) FindByHabitIDAndDate(ctx context.Context,
 		return nil, err
 	}
 
-	return &completion, nil
+	return completion, nil
+}
+
+func (r *PostgresCompletionRepository) FindAllByHabitID(ctx context.Context, habitID string) ([]*entity.HabitCompletion, error) {
+	return findAllByHabitID(ctx, r.db, habitID)
+}
+
+func findAllByHabitID(ctx context.Context, q queryer, habitID string) ([]*entity.HabitCompletion, error) {
+	query := `
+		SELECT ` + completionColumns + `
+		FROM habit_completions
+		WHERE habit_id = $1
+		ORDER BY completion_date ASC
+	`
+
+	rows, err := q.QueryContext(ctx, query, habitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var completions []*entity.HabitCompletion
+	for rows.Next() {
+		completion, err := scanCompletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		completions = append(completions, completion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return completions, nil
+}
+
+// FindAllByUserIDInRange returns every completion of the user between the two dates, inclusive
+func (r *PostgresCompletionRepository) FindAllByUserIDInRange(ctx context.Context, userID string, startDate, endDate time.Time) ([]*entity.HabitCompletion, error) {
+	query := `
+		SELECT ` + completionColumns + `
+		FROM habit_completions
+		WHERE user_id = $1 AND completion_date >= $2 AND completion_date <= $3
+		ORDER BY completion_date ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var completions []*entity.HabitCompletion
+	for rows.Next() {
+		completion, err := scanCompletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		completions = append(completions, completion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return completions, nil
 }
 
-func (r *PostgresCompletionRepository) Update(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit) error {
+// AggregateDailyByUserID sums the user's completions per day between the two dates,
+// inclusive. Days without completions are left out. Rather than counting fulfilled
+// habits and slips, it lists the habits concerned, since only those expected on the
+// day count and that depends on each habit's schedule.
+func (r *PostgresCompletionRepository) AggregateDailyByUserID(ctx context.Context, userID string, habitID *string, startDate, endDate time.Time) ([]*entity.HeatmapDay, error) {
+	conditions := []string{"c.user_id = $1", "c.completion_date >= $2", "c.completion_date <= $3"}
+	args := []interface{}{userID, startDate, endDate}
+
+	if habitID != nil {
+		conditions = append(conditions, "c.habit_id = $4")
+		args = append(args, *habitID)
+	}
+
+	// Entries of quit habits are slips, so they are counted apart from everything else
+	query := fmt.Sprintf(`
+		SELECT c.completion_date,
+			COALESCE(SUM(c.count) FILTER (WHERE h.kind <> 'quit'), 0),
+			COALESCE(SUM(c.value) FILTER (WHERE h.kind <> 'quit'), 0),
+			COALESCE(string_agg(c.habit_id::text, ',') FILTER (WHERE h.kind <> 'quit' AND CASE WHEN c.target_value IS NULL THEN c.count >= c.target_count ELSE c.value >= c.target_value END), ''),
+			COALESCE(string_agg(c.habit_id::text, ',') FILTER (WHERE h.kind = 'quit'), '')
+		FROM habit_completions c
+		JOIN habits h ON h.id = c.habit_id
+		WHERE %s
+		GROUP BY c.completion_date
+		ORDER BY c.completion_date ASC
+	`, strings.Join(conditions, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []*entity.HeatmapDay
+	for rows.Next() {
+		var day entity.HeatmapDay
+		var fulfilledHabitIDs, slipHabitIDs string
+		if err := rows.Scan(&day.Date, &day.Count, &day.Value, &fulfilledHabitIDs, &slipHabitIDs); err != nil {
+			return nil, err
+		}
+		day.Date = entity.DateOnly(day.Date)
+		day.FulfilledHabitIDs = splitIDs(fulfilledHabitIDs)
+		day.SlipHabitIDs = splitIDs(slipHabitIDs)
+		days = append(days, &day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
+
+func (r *PostgresCompletionRepository) Update(ctx context.Context, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Update completion
-	completionQuery := `
-		UPDATE habit_completions
-		SET count = $1, notes = $2
-		WHERE id = $3
-	`
-	_, err = tx.ExecContext(ctx, completionQuery, completion.Count, completion.Notes, completion.ID)
-	if err != nil {
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
 		return err
 	}
 
-	// Update habit statistics
-	habitQuery := `
-		UPDATE habits
-		SET total_completions = $1
-		WHERE id = $2
+	// Update completion
+	completionQuery := `
+		UPDATE habit_completions
+		SET count = $1, value = $2, notes = $3
+		WHERE id = $4
 	`
-	result, err := tx.ExecContext(ctx, habitQuery, habit.TotalCompletions, habit.ID)
+	result, err := tx.ExecContext(ctx, completionQuery, completion.Count, completion.Value, completion.Notes, completion.ID)
 	if err != nil {
 		return err
 	}
@@ -332,6 +610,11 @@ func (r *PostgresCompletionRepository) Update(ctx context.Context, completion *e
 		return apperrors.ErrNotFound
 	}
 
+	// Rebuild habit statistics from the updated history
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -378,3 +661,11 @@ func (r *PostgresCompletionRepository) CountByUserID(ctx context.Context, userID
 
 	return count, nil
 }
+
+// splitIDs splits a comma separated list of IDs, which is empty when there are none
+func splitIDs(ids string) []string {
+	if ids == "" {
+		return nil
+	}
+	return strings.Split(ids, ",")
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+type EmailTokenRepository interface {
+	Create(ctx context.Context, token *entity.EmailToken) error
+	Consume(ctx context.Context, tokenHash string, purpose entity.EmailTokenPurpose, now time.Time) (*entity.EmailToken, error)
+}
+
+type PostgresEmailTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresEmailTokenRepository(db *sql.DB) EmailTokenRepository {
+	return &PostgresEmailTokenRepository{db: db}
+}
+
+// Create stores a token, replacing the tokens of the same purpose sent to the user
+// before so only the latest email works. The user's expired tokens are cleaned up on
+// the way.
+func (r *PostgresEmailTokenRepository) Create(ctx context.Context, token *entity.EmailToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM email_tokens
+		WHERE user_id = $1 AND (purpose = $2 OR expires_at <= $3)
+	`, token.UserID, token.Purpose, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO email_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = tx.ExecContext(ctx, query, token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Consume marks the token with the hash as used and returns it. A token that is unknown,
+// of another purpose, expired or already used returns apperrors.ErrNotFound.
+func (r *PostgresEmailTokenRepository) Consume(ctx context.Context, tokenHash string, purpose entity.EmailTokenPurpose, now time.Time) (*entity.EmailToken, error) {
+	query := `
+		UPDATE email_tokens
+		SET used_at = $1
+		WHERE token_hash = $2 AND purpose = $3 AND used_at IS NULL AND expires_at > $1
+		RETURNING id, user_id, purpose, token_hash, expires_at, used_at, created_at
+	`
+
+	var token entity.EmailToken
+	err := r.db.QueryRowContext(ctx, query, now, tokenHash, purpose).Scan(
+		&token.ID, &token.UserID, &token.Purpose, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
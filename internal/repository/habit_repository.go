@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/uygardeniz/habit-tracker/internal/apperrors"
 	"github.com/uygardeniz/habit-tracker/internal/entity"
@@ -13,7 +15,9 @@ type HabitRepository interface {
 	Create(ctx context.Context, habit *entity.Habit) (*entity.Habit, error)
 	FindByID(ctx context.Context, id string) (*entity.Habit, error)
 	FindByUserID(ctx context.Context, userID string) ([]*entity.Habit, error)
-	Update(ctx context.Context, habit *entity.Habit) error
+	// Update stores the habit and rebuilds its stats as of today. A non-nil revision
+	// records a schedule change.
+	Update(ctx context.Context, habit *entity.Habit, revision *entity.ScheduleRevision, today time.Time) error
 	Delete(ctx context.Context, id string) error
 }
 
@@ -25,65 +29,14 @@ func NewPostgresHabitRepository(db *sql.DB) HabitRepository {
 	return &PostgresHabitRepository{db: db}
 }
 
-func (r *PostgresHabitRepository) Create(ctx context.Context, habit *entity.Habit) (*entity.Habit, error) {
-	query := `
-		INSERT INTO habits (id, user_id, name, description, motivation, color, category, frequency, target_count, target_days, current_streak, best_streak, total_completions, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
-		RETURNING id, user_id, name, description, motivation, color, category, frequency, target_count, target_days, current_streak, best_streak, total_completions, is_active, created_at, updated_at
-	`
-
-	var targetDaysJSON []byte
-	if habit.TargetDays != nil {
-		var err error
-		targetDaysJSON, err = json.Marshal(habit.TargetDays)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	row := r.db.QueryRowContext(ctx, query,
-		habit.ID, habit.UserID, habit.Name, habit.Description, habit.Motivation,
-		habit.Color, habit.Category, habit.Frequency, habit.TargetCount,
-		targetDaysJSON, habit.CurrentStreak, habit.BestStreak,
-		habit.TotalCompletions, habit.IsActive, habit.CreatedAt, habit.UpdatedAt,
-	)
-
-	var createdHabit entity.Habit
-	var targetDaysBytes []byte
-
-	err := row.Scan(
-		&createdHabit.ID, &createdHabit.UserID, &createdHabit.Name,
-		&createdHabit.Description, &createdHabit.Motivation, &createdHabit.Color,
-		&createdHabit.Category, &createdHabit.Frequency, &createdHabit.TargetCount,
-		&targetDaysBytes, &createdHabit.CurrentStreak, &createdHabit.BestStreak,
-		&createdHabit.TotalCompletions, &createdHabit.IsActive,
-		&createdHabit.CreatedAt, &createdHabit.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Handle target_days JSON conversion
-	if targetDaysBytes != nil {
-		var targetDays entity.TargetDays
-		if err := json.Unmarshal(targetDaysBytes, &targetDays); err != nil {
-			return nil, err
-		}
-		createdHabit.TargetDays = &targetDays
-	}
+const habitColumns = `id, user_id, name, description, motivation, color, category, kind, unit, frequency, target_count, target_days, period_target, interval_days, anchor_date, recurrence, target_value, current_streak, best_streak, total_completions, is_active, created_at, updated_at`
 
-	return &createdHabit, nil
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func (r *PostgresHabitRepository) FindByID(ctx context.Context, id string) (*entity.Habit, error) {
-	query := `
-		SELECT id, user_id, name, description, motivation, color, category, frequency, target_count, target_days, current_streak, best_streak, total_completions, is_active, created_at, updated_at
-		FROM habits
-		WHERE id = $1
-	`
-	row := r.db.QueryRowContext(ctx, query, id)
-
+func scanHabit(row rowScanner) (*entity.Habit, error) {
 	var habit entity.Habit
 	var targetDaysBytes []byte
 
@@ -95,9 +48,16 @@ func (r *PostgresHabitRepository) FindByID(ctx context.Context, id string) (*ent
 		&habit.Motivation,
 		&habit.Color,
 		&habit.Category,
+		&habit.Kind,
+		&habit.Unit,
 		&habit.Frequency,
 		&habit.TargetCount,
 		&targetDaysBytes,
+		&habit.PeriodTarget,
+		&habit.IntervalDays,
+		&habit.AnchorDate,
+		&habit.Recurrence,
+		&habit.TargetValue,
 		&habit.CurrentStreak,
 		&habit.BestStreak,
 		&habit.TotalCompletions,
@@ -107,12 +67,10 @@ func (r *PostgresHabitRepository) FindByID(ctx context.Context, id string) (*ent
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, apperrors.ErrNotFound
-		}
 		return nil, err
 	}
 
+	// Handle target_days JSON conversion
 	if targetDaysBytes != nil {
 		var targetDays entity.TargetDays
 		if err := json.Unmarshal(targetDaysBytes, &targetDays); err != nil {
@@ -124,9 +82,85 @@ func (r *PostgresHabitRepository) FindByID(ctx context.Context, id string) (*ent
 	return &habit, nil
 }
 
+func marshalTargetDays(targetDays *entity.TargetDays) ([]byte, error) {
+	if targetDays == nil {
+		return nil, nil
+	}
+	return json.Marshal(targetDays)
+}
+
+func (r *PostgresHabitRepository) Create(ctx context.Context, habit *entity.Habit) (*entity.Habit, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO habits (` + habitColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+		RETURNING ` + habitColumns
+
+	targetDaysJSON, err := marshalTargetDays(habit.TargetDays)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRowContext(ctx, query,
+		habit.ID, habit.UserID, habit.Name, habit.Description, habit.Motivation,
+		habit.Color, habit.Category, habit.Kind, habit.Unit, habit.Frequency, habit.TargetCount,
+		targetDaysJSON, habit.PeriodTarget, habit.IntervalDays, habit.AnchorDate,
+		habit.Recurrence, habit.TargetValue, habit.CurrentStreak, habit.BestStreak,
+		habit.TotalCompletions, habit.IsActive, habit.CreatedAt, habit.UpdatedAt,
+	)
+
+	createdHabit, err := scanHabit(row)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range habit.ScheduleHistory {
+		if err := saveScheduleRevision(ctx, tx, revision); err != nil {
+			return nil, err
+		}
+	}
+	createdHabit.ScheduleHistory = habit.ScheduleHistory
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return createdHabit, nil
+}
+
+func (r *PostgresHabitRepository) FindByID(ctx context.Context, id string) (*entity.Habit, error) {
+	query := `
+		SELECT ` + habitColumns + `
+		FROM habits
+		WHERE id = $1
+	`
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	habit, err := scanHabit(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	histories, err := findScheduleHistories(ctx, r.db, habit.UserID, &habit.ID)
+	if err != nil {
+		return nil, err
+	}
+	habit.ScheduleHistory = histories[habit.ID]
+
+	return habit, nil
+}
+
 func (r *PostgresHabitRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.Habit, error) {
 	query := `
-		SELECT id, user_id, name, description, motivation, color, category, frequency, target_count, target_days, current_streak, best_streak, total_completions, is_active, created_at, updated_at
+		SELECT ` + habitColumns + `
 		FROM habits
 		WHERE user_id = $1
 	`
@@ -138,65 +172,71 @@ func (r *PostgresHabitRepository) FindByUserID(ctx context.Context, userID strin
 
 	var habits []*entity.Habit
 	for rows.Next() {
-		var habit entity.Habit
-		var targetDaysBytes []byte
-		err := rows.Scan(
-			&habit.ID,
-			&habit.UserID,
-			&habit.Name,
-			&habit.Description,
-			&habit.Motivation,
-			&habit.Color,
-			&habit.Category,
-			&habit.Frequency,
-			&habit.TargetCount,
-			&targetDaysBytes,
-			&habit.CurrentStreak,
-			&habit.BestStreak,
-			&habit.TotalCompletions,
-			&habit.IsActive,
-			&habit.CreatedAt,
-			&habit.UpdatedAt,
-		)
+		habit, err := scanHabit(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		if targetDaysBytes != nil {
-			var targetDays entity.TargetDays
-			if err := json.Unmarshal(targetDaysBytes, &targetDays); err != nil {
-				return nil, err
-			}
-			habit.TargetDays = &targetDays
-		}
-
-		habits = append(habits, &habit)
+		habits = append(habits, habit)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
+	histories, err := findScheduleHistories(ctx, r.db, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, habit := range habits {
+		habit.ScheduleHistory = histories[habit.ID]
+	}
+
 	return habits, nil
 }
 
-func (r *PostgresHabitRepository) Update(ctx context.Context, habit *entity.Habit) error {
+func (r *PostgresHabitRepository) Update(ctx context.Context, habit *entity.Habit, revision *entity.ScheduleRevision, today time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return err
+	}
+
+	// Past entries mean different things under different kinds, so the kind is fixed
+	// once there are any
+	var storedKind string
+	var hasHistory bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT kind,
+			EXISTS (SELECT 1 FROM habit_completions WHERE habit_id = $1) OR
+			EXISTS (SELECT 1 FROM timer_sessions WHERE habit_id = $1)
+		FROM habits
+		WHERE id = $1
+	`, habit.ID).Scan(&storedKind, &hasHistory)
+	if err != nil {
+		return err
+	}
+
+	if storedKind != habit.Kind && hasHistory {
+		return entity.ErrKindChangeWithHistory
+	}
+
 	query := `
 		UPDATE habits
-		SET name = $1, description = $2, motivation = $3, color = $4, category = $5, frequency = $6, target_count = $7, target_days = $8, current_streak = $9, best_streak = $10, total_completions = $11, is_active = $12, updated_at = $13
-		WHERE id = $14
+		SET name = $1, description = $2, motivation = $3, color = $4, category = $5, kind = $6, unit = $7, frequency = $8, target_count = $9, target_days = $10, period_target = $11, interval_days = $12, anchor_date = $13, recurrence = $14, target_value = $15, is_active = $16, updated_at = $17
+		WHERE id = $18
 	`
 
-	var targetDaysJSON []byte
-	var err error
-	if habit.TargetDays != nil {
-		targetDaysJSON, err = json.Marshal(habit.TargetDays)
-		if err != nil {
-			return err
-		}
+	targetDaysJSON, err := marshalTargetDays(habit.TargetDays)
+	if err != nil {
+		return err
 	}
 
-	result, err := r.db.ExecContext(ctx, query, habit.Name, habit.Description, habit.Motivation, habit.Color, habit.Category, habit.Frequency, habit.TargetCount, targetDaysJSON, habit.CurrentStreak, habit.BestStreak, habit.TotalCompletions, habit.IsActive, habit.UpdatedAt, habit.ID)
+	result, err := tx.ExecContext(ctx, query, habit.Name, habit.Description, habit.Motivation, habit.Color, habit.Category, habit.Kind, habit.Unit, habit.Frequency, habit.TargetCount, targetDaysJSON, habit.PeriodTarget, habit.IntervalDays, habit.AnchorDate, habit.Recurrence, habit.TargetValue, habit.IsActive, habit.UpdatedAt, habit.ID)
 
 	if err != nil {
 		return err
@@ -211,7 +251,20 @@ func (r *PostgresHabitRepository) Update(ctx context.Context, habit *entity.Habi
 		return apperrors.ErrNotFound
 	}
 
-	return nil
+	if revision != nil {
+		if err := saveScheduleRevision(ctx, tx, revision); err != nil {
+			return err
+		}
+	}
+
+	// The stats are recalculated under the lock rather than taken from the habit, which
+	// was loaded before it and may miss completions logged since. Past days may also
+	// now fall under a different schedule.
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *PostgresHabitRepository) Delete(ctx context.Context, id string) error {
@@ -228,3 +281,104 @@ func (r *PostgresHabitRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+const scheduleRevisionColumns = `id, habit_id, effective_from, frequency, target_count, target_days, period_target, interval_days, anchor_date, recurrence, target_value, created_at`
+
+func scanScheduleRevision(row rowScanner) (*entity.ScheduleRevision, error) {
+	var revision entity.ScheduleRevision
+	var targetDaysBytes []byte
+
+	err := row.Scan(
+		&revision.ID,
+		&revision.HabitID,
+		&revision.EffectiveFrom,
+		&revision.Frequency,
+		&revision.TargetCount,
+		&targetDaysBytes,
+		&revision.PeriodTarget,
+		&revision.IntervalDays,
+		&revision.AnchorDate,
+		&revision.Recurrence,
+		&revision.TargetValue,
+		&revision.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if targetDaysBytes != nil {
+		var targetDays entity.TargetDays
+		if err := json.Unmarshal(targetDaysBytes, &targetDays); err != nil {
+			return nil, err
+		}
+		revision.TargetDays = &targetDays
+	}
+
+	return &revision, nil
+}
+
+// saveScheduleRevision stores a revision within the given transaction. It replaces the
+// revision taking effect on the same day, and any dated after it, which it supersedes.
+func saveScheduleRevision(ctx context.Context, tx *sql.Tx, revision *entity.ScheduleRevision) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM habit_schedule_revisions WHERE habit_id = $1 AND effective_from >= $2`, revision.HabitID, revision.EffectiveFrom)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO habit_schedule_revisions (` + scheduleRevisionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	targetDaysJSON, err := marshalTargetDays(revision.TargetDays)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query,
+		revision.ID, revision.HabitID, revision.EffectiveFrom, revision.Frequency, revision.TargetCount,
+		targetDaysJSON, revision.PeriodTarget, revision.IntervalDays, revision.AnchorDate,
+		revision.Recurrence, revision.TargetValue, revision.CreatedAt,
+	)
+
+	return err
+}
+
+// findScheduleHistories returns the schedule revisions of the user's habits, or of a
+// single one, keyed by habit ID and ordered by the day they took effect
+func findScheduleHistories(ctx context.Context, q queryer, userID string, habitID *string) (map[string][]*entity.ScheduleRevision, error) {
+	query := `
+		SELECT ` + scheduleRevisionColumns + `
+		FROM habit_schedule_revisions
+		WHERE habit_id IN (SELECT id FROM habits WHERE user_id = $1)
+	`
+	args := []any{userID}
+
+	if habitID != nil {
+		args = append(args, *habitID)
+		query += fmt.Sprintf(" AND habit_id = $%d", len(args))
+	}
+	query += " ORDER BY effective_from ASC"
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := make(map[string][]*entity.ScheduleRevision)
+	for rows.Next() {
+		revision, err := scanScheduleRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		histories[revision.HabitID] = append(histories[revision.HabitID], revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
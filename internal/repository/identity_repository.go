@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+	CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error
+	CreateVerifyingEmail(ctx context.Context, identity *entity.UserIdentity, now time.Time) error
+	FindByID(ctx context.Context, id string) (*entity.UserIdentity, error)
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+	FindByUserID(ctx context.Context, userID string) ([]*entity.UserIdentity, error)
+	Delete(ctx context.Context, identity *entity.UserIdentity) error
+}
+
+type PostgresIdentityRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresIdentityRepository(db *sql.DB) IdentityRepository {
+	return &PostgresIdentityRepository{db: db}
+}
+
+const identityColumns = `id, user_id, provider, subject, email, created_at`
+
+func scanIdentity(row rowScanner) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+
+	err := row.Scan(
+		&identity.ID, &identity.UserID, &identity.Provider,
+		&identity.Subject, &identity.Email, &identity.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// Create links an identity to an existing user. An identity that is already linked, or
+// a second one of the same provider for the user, returns apperrors.ErrAlreadyExists.
+func (r *PostgresIdentityRepository) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateVerifyingEmail links an identity whose provider vouches for the user's email
+// address to the user, and marks the address verified like
+// UserRepository.MarkEmailVerified does. An identity that is already linked returns
+// apperrors.ErrAlreadyExists and leaves the user as they were.
+func (r *PostgresIdentityRepository) CreateVerifyingEmail(ctx context.Context, identity *entity.UserIdentity, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := verifyEmail(ctx, tx, identity.UserID, "", now); err != nil {
+		return err
+	}
+
+	if err := insertIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateWithUser registers a new user together with the identity they signed up with.
+// A user with the same email returns apperrors.ErrAlreadyExists.
+func (r *PostgresIdentityRepository) CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertUser(ctx, tx, user); err != nil {
+		return err
+	}
+
+	if err := insertIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresIdentityRepository) FindByID(ctx context.Context, id string) (*entity.UserIdentity, error) {
+	query := `
+		SELECT ` + identityColumns + `
+		FROM user_identities
+		WHERE id = $1
+	`
+
+	identity, err := scanIdentity(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func (r *PostgresIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	query := `
+		SELECT ` + identityColumns + `
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	identity, err := scanIdentity(r.db.QueryRowContext(ctx, query, provider, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func (r *PostgresIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]*entity.UserIdentity, error) {
+	query := `
+		SELECT ` + identityColumns + `
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []*entity.UserIdentity{}
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// Delete unlinks an identity. The user is locked while counting their identities and
+// password, and unlinking the only one of them returns entity.ErrLastLoginMethod.
+func (r *PostgresIdentityRepository) Delete(ctx context.Context, identity *entity.UserIdentity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockUser(ctx, tx, identity.UserID); err != nil {
+		return err
+	}
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM user_identities WHERE user_id = $1) +
+			(SELECT COUNT(*) FROM user_passwords WHERE user_id = $1)
+	`
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, identity.UserID).Scan(&count); err != nil {
+		return err
+	}
+
+	if count <= 1 {
+		return entity.ErrLastLoginMethod
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM user_identities WHERE id = $1`, identity.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func insertIdentity(ctx context.Context, tx *sql.Tx, identity *entity.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (` + identityColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		identity.ID, identity.UserID, identity.Provider,
+		identity.Subject, identity.Email, identity.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// insertUser inserts a new user. A user with the same email, whatever its case, returns
+// apperrors.ErrAlreadyExists.
+func insertUser(ctx context.Context, tx *sql.Tx, user *entity.User) error {
+	query := `
+		INSERT INTO users (id, email, name, picture, timezone, email_verified_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query, user.ID, user.Email, user.Name, user.Picture, user.Timezone, user.EmailVerifiedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// lockUser locks the user's row within the given transaction
+func lockUser(ctx context.Context, tx *sql.Tx, userID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperrors.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
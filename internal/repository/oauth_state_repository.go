@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type OAuthStateRepository interface {
+	Use(ctx context.Context, state string, expiresAt, now time.Time) (bool, error)
+}
+
+type PostgresOAuthStateRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresOAuthStateRepository(db *sql.DB) OAuthStateRepository {
+	return &PostgresOAuthStateRepository{db: db}
+}
+
+// Use records the state as used until expiresAt and reports whether it hadn't been used
+// before. Expired states are cleaned up on the way.
+func (r *PostgresOAuthStateRepository) Use(ctx context.Context, state string, expiresAt, now time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM used_oauth_states WHERE expires_at <= $1`, now); err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO used_oauth_states (state, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (state) DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query, state, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+type PasswordRepository interface {
+	CreateWithUser(ctx context.Context, user *entity.User, password *entity.UserPassword) error
+	FindByUserID(ctx context.Context, userID string) (*entity.UserPassword, error)
+	Save(ctx context.Context, password *entity.UserPassword) error
+	Reset(ctx context.Context, password *entity.UserPassword, now time.Time) error
+	VerifyEmail(ctx context.Context, password *entity.UserPassword, now time.Time) error
+}
+
+type PostgresPasswordRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPasswordRepository(db *sql.DB) PasswordRepository {
+	return &PostgresPasswordRepository{db: db}
+}
+
+// CreateWithUser registers a new user together with the password they signed up with.
+// A user with the same email returns apperrors.ErrAlreadyExists.
+func (r *PostgresPasswordRepository) CreateWithUser(ctx context.Context, user *entity.User, password *entity.UserPassword) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertUser(ctx, tx, user); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_passwords (user_id, password_hash, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err = tx.ExecContext(ctx, query, password.UserID, password.PasswordHash, password.CreatedAt, password.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresPasswordRepository) FindByUserID(ctx context.Context, userID string) (*entity.UserPassword, error) {
+	query := `
+		SELECT user_id, password_hash, created_at, updated_at
+		FROM user_passwords
+		WHERE user_id = $1
+	`
+
+	var password entity.UserPassword
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&password.UserID, &password.PasswordHash, &password.CreatedAt, &password.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &password, nil
+}
+
+const savePasswordQuery = `
+	INSERT INTO user_passwords (user_id, password_hash, created_at, updated_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id) DO UPDATE
+	SET password_hash = EXCLUDED.password_hash, updated_at = EXCLUDED.updated_at
+`
+
+// Save sets the user's password, replacing the one they had
+func (r *PostgresPasswordRepository) Save(ctx context.Context, password *entity.UserPassword) error {
+	_, err := r.db.ExecContext(ctx, savePasswordQuery, password.UserID, password.PasswordHash, password.CreatedAt, password.UpdatedAt)
+	return err
+}
+
+// Reset sets the password of a user who proved they own their email address. The
+// address is marked verified and every session of the user is revoked.
+func (r *PostgresPasswordRepository) Reset(ctx context.Context, password *entity.UserPassword, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := verifyEmail(ctx, tx, password.UserID, "", now); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, savePasswordQuery, password.UserID, password.PasswordHash, password.CreatedAt, password.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := revokeUserSessions(ctx, tx, password.UserID, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// VerifyEmail marks the email address of the password's user verified by someone who
+// knows the password. The password is kept unless it was changed in the meantime.
+func (r *PostgresPasswordRepository) VerifyEmail(ctx context.Context, password *entity.UserPassword, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := verifyEmail(ctx, tx, password.UserID, password.PasswordHash, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
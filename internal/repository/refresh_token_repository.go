@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+type RefreshTokenRepository interface {
+	Rotate(ctx context.Context, tokenID, nextID, userAgent, ipAddress string, now time.Time) (*entity.RefreshToken, error)
+	RevokeFamily(ctx context.Context, tokenID string, now time.Time) error
+}
+
+type PostgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{db: db}
+}
+
+const refreshTokenColumns = `id, user_id, family_id, expires_at, used_at, revoked_at, created_at`
+
+func scanRefreshToken(row rowScanner) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.ExpiresAt,
+		&token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// Rotate exchanges a token for a new one of the same family, created with nextID, and
+// records the use on the token's session. A token that was already used revokes its
+// whole family and returns entity.ErrRefreshTokenReused; revoked and expired tokens,
+// and those of ended sessions, return apperrors.ErrUnauthorized.
+func (r *PostgresRefreshTokenRepository) Rotate(ctx context.Context, tokenID, nextID, userAgent, ipAddress string, now time.Time) (*entity.RefreshToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens
+		WHERE id = $1
+		FOR UPDATE
+	`
+	token, err := scanRefreshToken(tx.QueryRowContext(ctx, query, tokenID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	sessionQuery := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE id = $1
+		FOR UPDATE
+	`
+	session, err := scanSession(tx.QueryRowContext(ctx, sessionQuery, token.FamilyID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if token.UsedAt != nil {
+		if err := revokeSession(ctx, tx, session.ID, now); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, entity.ErrRefreshTokenReused
+	}
+
+	if !token.IsUsable(now) || !session.IsActive(now) {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = $1 WHERE id = $2`, now, token.ID); err != nil {
+		return nil, err
+	}
+
+	next := token.Rotate(nextID, now)
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return nil, err
+	}
+
+	session.Touch(userAgent, ipAddress, now)
+	_, err = tx.ExecContext(ctx, `UPDATE user_sessions SET user_agent = $1, ip_address = $2, last_used_at = $3, expires_at = $4 WHERE id = $5`,
+		session.UserAgent, session.IPAddress, session.LastUsedAt, session.ExpiresAt, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// RevokeFamily ends the session of the token, revoking it and every other token rotated
+// from the same login
+func (r *PostgresRefreshTokenRepository) RevokeFamily(ctx context.Context, tokenID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var familyID string
+	err = tx.QueryRowContext(ctx, `SELECT family_id FROM refresh_tokens WHERE id = $1`, tokenID).Scan(&familyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperrors.ErrNotFound
+		}
+		return err
+	}
+
+	if err := revokeSession(ctx, tx, familyID, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertRefreshToken(ctx context.Context, tx *sql.Tx, token *entity.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (` + refreshTokenColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		token.ID, token.UserID, token.FamilyID, token.ExpiresAt,
+		token.UsedAt, token.RevokedAt, token.CreatedAt,
+	)
+
+	return err
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error
+	FindByID(ctx context.Context, id string) (*entity.Session, error)
+	FindActiveByUserID(ctx context.Context, userID string, now time.Time) ([]*entity.Session, error)
+	Revoke(ctx context.Context, id string, now time.Time) error
+	RevokeAllByUserID(ctx context.Context, userID string, now time.Time) error
+}
+
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSessionRepository(db *sql.DB) SessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row rowScanner) (*entity.Session, error) {
+	var session entity.Session
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &session.RevokedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Create stores a new session with its first refresh token and drops the user's
+// sessions that have expired
+func (r *PostgresSessionRepository) Create(ctx context.Context, session *entity.Session, token *entity.RefreshToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_sessions WHERE user_id = $1 AND expires_at < $2`, session.UserID, session.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_sessions (` + sessionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		session.ID, session.UserID, session.UserAgent, session.IPAddress,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt, session.RevokedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRefreshToken(ctx, tx, token); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresSessionRepository) FindByID(ctx context.Context, id string) (*entity.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE id = $1
+	`
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// FindActiveByUserID returns the user's sessions that are neither revoked nor expired,
+// most recently used first
+func (r *PostgresSessionRepository) FindActiveByUserID(ctx context.Context, userID string, now time.Time) ([]*entity.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*entity.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Revoke ends the session together with its refresh tokens
+func (r *PostgresSessionRepository) Revoke(ctx context.Context, id string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM user_sessions WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return apperrors.ErrNotFound
+	}
+
+	if err := revokeSession(ctx, tx, id, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeAllByUserID ends every session of the user
+func (r *PostgresSessionRepository) RevokeAllByUserID(ctx context.Context, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := revokeUserSessions(ctx, tx, userID, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// revokeUserSessions revokes every session of the user and their refresh tokens
+func revokeUserSessions(ctx context.Context, tx *sql.Tx, userID string, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `UPDATE user_sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`, now, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`, now, userID)
+	return err
+}
+
+// revokeSession revokes the session and its family of refresh tokens
+func revokeSession(ctx context.Context, tx *sql.Tx, sessionID string, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `UPDATE user_sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, now, sessionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`, now, sessionID)
+	return err
+}
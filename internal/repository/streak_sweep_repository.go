@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+type StreakSweepRepository interface {
+	FindPendingUserIDs(ctx context.Context, now time.Time) ([]string, error)
+	StartRun(ctx context.Context, userID string, sweepDate time.Time) error
+	ApplyReset(ctx context.Context, reset *entity.StreakReset) (bool, error)
+	CompleteRun(ctx context.Context, userID string, sweepDate time.Time) error
+}
+
+type PostgresStreakSweepRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresStreakSweepRepository(db *sql.DB) StreakSweepRepository {
+	return &PostgresStreakSweepRepository{db: db}
+}
+
+// FindPendingUserIDs returns users that have at least one running streak or quit habit and
+// whose sweep for their current local day has not completed yet, including runs interrupted by a crash.
+// Local days are worked out in Go, as the sweep does, since Postgres may not know every
+// timezone Go does. Every timezone's day is within a day of UTC's, so only the runs of
+// those three days are loaded.
+func (r *PostgresStreakSweepRepository) FindPendingUserIDs(ctx context.Context, now time.Time) ([]string, error) {
+	query := `
+		SELECT u.id, u.timezone, s.sweep_date
+		FROM users u
+		LEFT JOIN streak_sweep_runs s
+		  ON s.user_id = u.id
+		  AND s.status = 'completed'
+		  AND s.sweep_date BETWEEN $1 AND $2
+		WHERE EXISTS (
+			SELECT 1 FROM habits h
+			WHERE h.user_id = u.id
+			  AND h.is_active = true
+			  AND (h.current_streak > 0 OR h.kind = 'quit')
+		)
+		ORDER BY u.id
+	`
+
+	utcToday := entity.DateOnly(now.UTC())
+	rows, err := r.db.QueryContext(ctx, query, utcToday.AddDate(0, 0, -1), utcToday.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	swept := make(map[string]bool)
+	for rows.Next() {
+		var user entity.User
+		var sweepDate sql.NullTime
+		if err := rows.Scan(&user.ID, &user.Timezone, &sweepDate); err != nil {
+			return nil, err
+		}
+		if _, seen := swept[user.ID]; !seen {
+			userIDs = append(userIDs, user.ID)
+			swept[user.ID] = false
+		}
+		if sweepDate.Valid && entity.DateOnly(sweepDate.Time).Equal(user.Today(now)) {
+			swept[user.ID] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := userIDs[:0]
+	for _, userID := range userIDs {
+		if !swept[userID] {
+			pending = append(pending, userID)
+		}
+	}
+
+	return pending, nil
+}
+
+// StartRun records the start of a sweep. Starting a run that already exists is a no-op
+// so an interrupted sweep can be resumed.
+func (r *PostgresStreakSweepRepository) StartRun(ctx context.Context, userID string, sweepDate time.Time) error {
+	query := `
+		INSERT INTO streak_sweep_runs (user_id, sweep_date, status)
+		VALUES ($1, $2, 'running')
+		ON CONFLICT (user_id, sweep_date) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, sweepDate)
+	return err
+}
+
+// ApplyReset breaks the habit's current streak and records the change in the same transaction.
+// The reset only applies while the stored streak still equals PreviousStreak, so a completion
+// logged concurrently with the sweep is never overwritten.
+func (r *PostgresStreakSweepRepository) ApplyReset(ctx context.Context, reset *entity.StreakReset) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	habitQuery := `
+		UPDATE habits
+		SET current_streak = 0
+		WHERE id = $1 AND current_streak = $2
+	`
+
+	result, err := tx.ExecContext(ctx, habitQuery, reset.HabitID, reset.PreviousStreak)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	resetQuery := `
+		INSERT INTO streak_sweep_resets (user_id, sweep_date, habit_id, previous_streak, reset_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, sweep_date, habit_id) DO NOTHING
+	`
+
+	_, err = tx.ExecContext(ctx, resetQuery, reset.UserID, reset.SweepDate, reset.HabitID, reset.PreviousStreak, reset.ResetAt)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *PostgresStreakSweepRepository) CompleteRun(ctx context.Context, userID string, sweepDate time.Time) error {
+	query := `
+		UPDATE streak_sweep_runs
+		SET status = 'completed', finished_at = NOW()
+		WHERE user_id = $1 AND sweep_date = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, sweepDate)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+// TimeOffRepository stores habit skips and user pauses. Adding or removing days off
+// changes streaks, so the writes recalculate the affected habits' stats as of today.
+type TimeOffRepository interface {
+	CreateSkip(ctx context.Context, skip *entity.HabitSkip, habit *entity.Habit, today time.Time) (*entity.HabitSkip, error)
+	FindSkipByID(ctx context.Context, id string) (*entity.HabitSkip, error)
+	DeleteSkip(ctx context.Context, id string, habit *entity.Habit, today time.Time) error
+	CreatePause(ctx context.Context, pause *entity.Pause, today time.Time) (*entity.Pause, error)
+	FindPauseByID(ctx context.Context, id string) (*entity.Pause, error)
+	FindPausesByUserID(ctx context.Context, userID string) ([]*entity.Pause, error)
+	DeletePause(ctx context.Context, pause *entity.Pause, today time.Time) error
+	FindByUserID(ctx context.Context, userID string, habitID *string, startDate, endDate *time.Time) (*entity.TimeOff, error)
+}
+
+type PostgresTimeOffRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTimeOffRepository(db *sql.DB) TimeOffRepository {
+	return &PostgresTimeOffRepository{db: db}
+}
+
+const skipColumns = `id, habit_id, user_id, skip_date, reason, created_at`
+
+const pauseColumns = `id, user_id, start_date, end_date, reason, created_at`
+
+func scanSkip(row rowScanner) (*entity.HabitSkip, error) {
+	var skip entity.HabitSkip
+
+	err := row.Scan(&skip.ID, &skip.HabitID, &skip.UserID, &skip.SkipDate, &skip.Reason, &skip.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &skip, nil
+}
+
+func scanPause(row rowScanner) (*entity.Pause, error) {
+	var pause entity.Pause
+
+	err := row.Scan(&pause.ID, &pause.UserID, &pause.StartDate, &pause.EndDate, &pause.Reason, &pause.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pause, nil
+}
+
+// CreateSkip stores the skip and recalculates the habit's stats. A habit can be skipped
+// once per day; skipping it again fails with ErrAlreadyExists.
+func (r *PostgresTimeOffRepository) CreateSkip(ctx context.Context, skip *entity.HabitSkip, habit *entity.Habit, today time.Time) (*entity.HabitSkip, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO habit_skips (` + skipColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (habit_id, skip_date) DO NOTHING
+		RETURNING ` + skipColumns
+
+	row := tx.QueryRowContext(ctx, query, skip.ID, skip.HabitID, skip.UserID, skip.SkipDate, skip.Reason, skip.CreatedAt)
+
+	createdSkip, err := scanSkip(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrAlreadyExists
+		}
+		return nil, err
+	}
+
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return createdSkip, nil
+}
+
+func (r *PostgresTimeOffRepository) FindSkipByID(ctx context.Context, id string) (*entity.HabitSkip, error) {
+	query := `
+		SELECT ` + skipColumns + `
+		FROM habit_skips
+		WHERE id = $1
+	`
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	skip, err := scanSkip(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return skip, nil
+}
+
+// DeleteSkip removes the skip and recalculates the habit's stats
+func (r *PostgresTimeOffRepository) DeleteSkip(ctx context.Context, id string, habit *entity.Habit, today time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM habit_skips WHERE id = $1 AND habit_id = $2`, id, habit.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreatePause stores the pause and recalculates the stats of all of the user's habits.
+// Pauses of a user can't overlap; a pause overlapping an existing one fails with
+// ErrAlreadyExists.
+func (r *PostgresTimeOffRepository) CreatePause(ctx context.Context, pause *entity.Pause, today time.Time) (*entity.Pause, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the user's habits serializes concurrent pauses of the same user
+	habits, err := lockUserHabits(ctx, tx, pause.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO user_pauses (` + pauseColumns + `)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE NOT EXISTS (
+			SELECT 1 FROM user_pauses
+			WHERE user_id = $2 AND start_date <= $4 AND end_date >= $3
+		)
+		RETURNING ` + pauseColumns
+
+	row := tx.QueryRowContext(ctx, query, pause.ID, pause.UserID, pause.StartDate, pause.EndDate, pause.Reason, pause.CreatedAt)
+
+	createdPause, err := scanPause(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrAlreadyExists
+		}
+		return nil, err
+	}
+
+	for _, habit := range habits {
+		if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return createdPause, nil
+}
+
+func (r *PostgresTimeOffRepository) FindPauseByID(ctx context.Context, id string) (*entity.Pause, error) {
+	query := `
+		SELECT ` + pauseColumns + `
+		FROM user_pauses
+		WHERE id = $1
+	`
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	pause, err := scanPause(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return pause, nil
+}
+
+func (r *PostgresTimeOffRepository) FindPausesByUserID(ctx context.Context, userID string) ([]*entity.Pause, error) {
+	return findPauses(ctx, r.db, userID, nil, nil)
+}
+
+// DeletePause removes the pause and recalculates the stats of all of the user's habits
+func (r *PostgresTimeOffRepository) DeletePause(ctx context.Context, pause *entity.Pause, today time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	habits, err := lockUserHabits(ctx, tx, pause.UserID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM user_pauses WHERE id = $1 AND user_id = $2`, pause.ID, pause.UserID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	for _, habit := range habits {
+		if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByUserID returns the user's days off between the two dates, inclusive: the skips
+// on those days, narrowed to one habit when habitID is set, and the pauses overlapping
+// them. A nil date leaves that side of the range open.
+func (r *PostgresTimeOffRepository) FindByUserID(ctx context.Context, userID string, habitID *string, startDate, endDate *time.Time) (*entity.TimeOff, error) {
+	return findTimeOff(ctx, r.db, userID, habitID, startDate, endDate)
+}
+
+func findTimeOff(ctx context.Context, q queryer, userID string, habitID *string, startDate, endDate *time.Time) (*entity.TimeOff, error) {
+	skips, err := findSkips(ctx, q, userID, habitID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	pauses, err := findPauses(ctx, q, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.NewTimeOff(skips, pauses), nil
+}
+
+func findSkips(ctx context.Context, q queryer, userID string, habitID *string, startDate, endDate *time.Time) ([]*entity.HabitSkip, error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+
+	if habitID != nil {
+		args = append(args, *habitID)
+		conditions = append(conditions, fmt.Sprintf("habit_id = $%d", len(args)))
+	}
+
+	if startDate != nil {
+		args = append(args, *startDate)
+		conditions = append(conditions, fmt.Sprintf("skip_date >= $%d", len(args)))
+	}
+
+	if endDate != nil {
+		args = append(args, *endDate)
+		conditions = append(conditions, fmt.Sprintf("skip_date <= $%d", len(args)))
+	}
+
+	query := `
+		SELECT ` + skipColumns + `
+		FROM habit_skips
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY skip_date ASC
+	`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skips := []*entity.HabitSkip{}
+	for rows.Next() {
+		skip, err := scanSkip(rows)
+		if err != nil {
+			return nil, err
+		}
+		skips = append(skips, skip)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return skips, nil
+}
+
+func findPauses(ctx context.Context, q queryer, userID string, startDate, endDate *time.Time) ([]*entity.Pause, error) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+
+	if startDate != nil {
+		args = append(args, *startDate)
+		conditions = append(conditions, fmt.Sprintf("end_date >= $%d", len(args)))
+	}
+
+	if endDate != nil {
+		args = append(args, *endDate)
+		conditions = append(conditions, fmt.Sprintf("start_date <= $%d", len(args)))
+	}
+
+	query := `
+		SELECT ` + pauseColumns + `
+		FROM user_pauses
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY start_date ASC
+	`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pauses := []*entity.Pause{}
+	for rows.Next() {
+		pause, err := scanPause(rows)
+		if err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, pause)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pauses, nil
+}
+
+// lockUserHabits locks and returns all of the user's habits within the given transaction
+func lockUserHabits(ctx context.Context, tx *sql.Tx, userID string) ([]*entity.Habit, error) {
+	query := `
+		SELECT ` + habitColumns + `
+		FROM habits
+		WHERE user_id = $1
+		ORDER BY id
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var habits []*entity.Habit
+	for rows.Next() {
+		habit, err := scanHabit(rows)
+		if err != nil {
+			return nil, err
+		}
+		habits = append(habits, habit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return habits, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+type TimerRepository interface {
+	Create(ctx context.Context, session *entity.TimerSession) (*entity.TimerSession, error)
+	FindActiveByHabitID(ctx context.Context, habitID string) (*entity.TimerSession, error)
+	Update(ctx context.Context, session *entity.TimerSession) error
+	Stop(ctx context.Context, session *entity.TimerSession, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) (*entity.HabitCompletion, error)
+}
+
+type PostgresTimerRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTimerRepository(db *sql.DB) TimerRepository {
+	return &PostgresTimerRepository{db: db}
+}
+
+const timerColumns = `id, habit_id, user_id, status, started_at, resumed_at, elapsed_seconds, stopped_at, completion_id, created_at, updated_at`
+
+func scanTimerSession(row rowScanner) (*entity.TimerSession, error) {
+	var session entity.TimerSession
+
+	err := row.Scan(
+		&session.ID, &session.HabitID, &session.UserID, &session.Status,
+		&session.StartedAt, &session.ResumedAt, &session.ElapsedSeconds, &session.StoppedAt,
+		&session.CompletionID, &session.CreatedAt, &session.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Create stores a new session. The habit is locked while checking for an open session,
+// so a habit never has more than one running or paused timer.
+func (r *PostgresTimerRepository) Create(ctx context.Context, session *entity.TimerSession) (*entity.TimerSession, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, session.HabitID); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM timer_sessions WHERE habit_id = $1 AND status <> 'stopped')`, session.HabitID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return nil, apperrors.ErrAlreadyExists
+	}
+
+	query := `
+		INSERT INTO timer_sessions (` + timerColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + timerColumns
+
+	row := tx.QueryRowContext(ctx, query,
+		session.ID, session.HabitID, session.UserID, session.Status,
+		session.StartedAt, session.ResumedAt, session.ElapsedSeconds, session.StoppedAt,
+		session.CompletionID, session.CreatedAt, session.UpdatedAt,
+	)
+
+	createdSession, err := scanTimerSession(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return createdSession, nil
+}
+
+// FindActiveByHabitID returns the habit's running or paused session
+func (r *PostgresTimerRepository) FindActiveByHabitID(ctx context.Context, habitID string) (*entity.TimerSession, error) {
+	query := `
+		SELECT ` + timerColumns + `
+		FROM timer_sessions
+		WHERE habit_id = $1 AND status <> 'stopped'
+	`
+	row := r.db.QueryRowContext(ctx, query, habitID)
+
+	session, err := scanTimerSession(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Update saves a paused or resumed session. Sessions that were stopped in the meantime
+// are left alone and reported as not found.
+func (r *PostgresTimerRepository) Update(ctx context.Context, session *entity.TimerSession) error {
+	query := `
+		UPDATE timer_sessions
+		SET status = $1, resumed_at = $2, elapsed_seconds = $3, updated_at = $4
+		WHERE id = $5 AND status <> 'stopped'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, session.Status, session.ResumedAt, session.ElapsedSeconds, session.UpdatedAt, session.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return apperrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Stop saves a stopped session and, when completion is not nil, adds its minutes to the
+// habit's completion for that day, all within one transaction. It returns the updated
+// completion.
+func (r *PostgresTimerRepository) Stop(ctx context.Context, session *entity.TimerSession, completion *entity.HabitCompletion, habit *entity.Habit, today time.Time) (*entity.HabitCompletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockHabit(ctx, tx, habit.ID); err != nil {
+		return nil, err
+	}
+
+	var updatedCompletion *entity.HabitCompletion
+	if completion != nil {
+		updatedCompletion, err = incrementCompletion(ctx, tx, completion)
+		if err != nil {
+			return nil, err
+		}
+		session.CompletionID = &updatedCompletion.ID
+	}
+
+	query := `
+		UPDATE timer_sessions
+		SET status = $1, resumed_at = $2, elapsed_seconds = $3, stopped_at = $4, completion_id = $5, updated_at = $6
+		WHERE id = $7 AND status <> 'stopped'
+	`
+
+	result, err := tx.ExecContext(ctx, query, session.Status, session.ResumedAt, session.ElapsedSeconds, session.StoppedAt, session.CompletionID, session.UpdatedAt, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	// Another request stopped the session first, so its minutes were already counted
+	if rowsAffected == 0 {
+		return nil, apperrors.ErrNotFound
+	}
+
+	if completion != nil {
+		if err := recalculateHabitStats(ctx, tx, habit, today); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return updatedCompletion, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/uygardeniz/habit-tracker/internal/apperrors"
 	"github.com/uygardeniz/habit-tracker/internal/entity"
@@ -10,8 +11,10 @@ import (
 
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
-	FindByGoogleID(ctx context.Context, googleID string) (*entity.User, error)
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	FindByID(ctx context.Context, id string) (*entity.User, error)
+	Update(ctx context.Context, user *entity.User) error
+	MarkEmailVerified(ctx context.Context, userID string, now time.Time) error
 }
 
 type PostgresUserRepository struct {
@@ -22,30 +25,9 @@ func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
 	return &PostgresUserRepository{db: db}
 }
 
-func (r *PostgresUserRepository) Create(ctx context.Context, user *entity.User) error {
-	query := `
-		INSERT INTO users (id, email, name, picture, google_id)
-		VALUES ($1, $2, $3, $4, $5)
-	`
-
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Email, user.Name, user.Picture, user.GoogleID)
-
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (r *PostgresUserRepository) FindByGoogleID(ctx context.Context, googleID string) (*entity.User, error) {
-	query := `
-		SELECT id, email, name, picture, google_id
-		FROM users
-		WHERE google_id = $1
-	`
-
-	row := r.db.QueryRowContext(ctx, query, googleID)
+const userColumns = `id, email, name, picture, timezone, email_verified_at, created_at, updated_at`
 
+func scanUser(row rowScanner) (*entity.User, error) {
 	var foundUser entity.User
 
 	err := row.Scan(
@@ -53,7 +35,10 @@ func (r *PostgresUserRepository) FindByGoogleID(ctx context.Context, googleID st
 		&foundUser.Email,
 		&foundUser.Name,
 		&foundUser.Picture,
-		&foundUser.GoogleID,
+		&foundUser.Timezone,
+		&foundUser.EmailVerifiedAt,
+		&foundUser.CreatedAt,
+		&foundUser.UpdatedAt,
 	)
 
 	if err != nil {
@@ -66,31 +51,104 @@ func (r *PostgresUserRepository) FindByGoogleID(ctx context.Context, googleID st
 	return &foundUser, nil
 }
 
+func (r *PostgresUserRepository) Create(ctx context.Context, user *entity.User) error {
+	query := `
+		INSERT INTO users (id, email, name, picture, timezone, email_verified_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Email, user.Name, user.Picture, user.Timezone, user.EmailVerifiedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE LOWER(email) = $1
+	`
+
+	return scanUser(r.db.QueryRowContext(ctx, query, entity.NormalizeEmail(email)))
+}
+
 func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
 	query := `
-		SELECT id, email, name, picture, google_id
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
+	return scanUser(r.db.QueryRowContext(ctx, query, id))
+}
 
-	var foundUser entity.User
+func (r *PostgresUserRepository) Update(ctx context.Context, user *entity.User) error {
+	query := `
+		UPDATE users
+		SET name = $1, picture = $2, timezone = $3
+		WHERE id = $4
+		RETURNING updated_at
+	`
 
-	err := row.Scan(
-		&foundUser.ID,
-		&foundUser.Email,
-		&foundUser.Name,
-		&foundUser.Picture,
-		&foundUser.GoogleID,
-	)
+	err := r.db.QueryRowContext(ctx, query, user.Name, user.Picture, user.Timezone, user.ID).Scan(&user.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperrors.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
 
+// MarkEmailVerified records that the user proved they own their email address, keeping
+// the time of the first proof. On the first proof the user's password is deleted and
+// their sessions revoked, see verifyEmail.
+func (r *PostgresUserRepository) MarkEmailVerified(ctx context.Context, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := verifyEmail(ctx, tx, userID, "", now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// verifyEmail marks the user's email address verified within the given transaction.
+// Anyone could have signed up with the address before its owner proved it, so the
+// first time every session of the user is revoked and their password is deleted,
+// unless its hash is keptPasswordHash because the owner just proved they know it.
+func verifyEmail(ctx context.Context, tx *sql.Tx, userID, keptPasswordHash string, now time.Time) error {
+	var verifiedAt *time.Time
+	err := tx.QueryRowContext(ctx, `SELECT email_verified_at FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&verifiedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, apperrors.ErrNotFound
+			return apperrors.ErrNotFound
 		}
-		return nil, err
+		return err
 	}
 
-	return &foundUser, nil
+	if verifiedAt != nil {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_passwords WHERE user_id = $1 AND password_hash <> $2`, userID, keptPasswordHash)
+	if err != nil {
+		return err
+	}
+
+	if err := revokeUserSessions(ctx, tx, userID, now); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET email_verified_at = $1 WHERE id = $2`, now, userID)
+	return err
 }
@@ -4,31 +4,55 @@ import (
 	"net/http"
 
 	"github.com/uygardeniz/habit-tracker/internal/app"
-	"github.com/uygardeniz/habit-tracker/internal/middleware"
 )
 
 func SetupRoutes(app *app.Application) http.Handler {
 	router := http.NewServeMux()
 	protectedMux := http.NewServeMux()
 	// Middleware
-	authMiddleware := middleware.NewAuthMiddleware(app.Logger)
+	authMiddleware := app.AuthMiddleware
 
 	// Authentication routes
-	router.HandleFunc("GET /api/auth/google/login", http.HandlerFunc(app.AuthHandler.HandleGoogleLogin))
-	router.HandleFunc("GET /api/auth/google/callback", app.AuthHandler.HandleGoogleCallback)
+	router.HandleFunc("GET /api/auth/providers", app.AuthHandler.HandleProviders)
+	router.HandleFunc("GET /api/auth/{provider}/login", app.AuthHandler.HandleLogin)
+	router.HandleFunc("GET /api/auth/{provider}/callback", app.AuthHandler.HandleCallback)
 	router.HandleFunc("GET /api/auth/refresh_token", app.AuthHandler.HandleRefreshToken)
 	router.HandleFunc("GET /api/auth/session", app.AuthHandler.HandleGetUserAndAccessToken)
 	router.HandleFunc("POST /api/auth/logout", app.AuthHandler.HandleLogout)
 
+	// Email authentication routes
+	router.HandleFunc("POST /api/auth/register", app.EmailAuthHandler.Register)
+	router.HandleFunc("POST /api/auth/login", app.EmailAuthHandler.Login)
+	router.HandleFunc("POST /api/auth/verify-email", app.EmailAuthHandler.VerifyEmail)
+	router.HandleFunc("POST /api/auth/verify-email/resend", app.EmailAuthHandler.ResendVerificationEmail)
+	router.HandleFunc("POST /api/auth/password/forgot", app.EmailAuthHandler.ForgotPassword)
+	router.HandleFunc("POST /api/auth/password/reset", app.EmailAuthHandler.ResetPassword)
+	router.HandleFunc("POST /api/auth/magic-link", app.EmailAuthHandler.RequestMagicLink)
+	router.HandleFunc("POST /api/auth/magic-link/login", app.EmailAuthHandler.LoginWithMagicLink)
+
 	// User routes
 	protectedMux.HandleFunc("GET /api/user/me", app.UserHandler.GetMe)
+	protectedMux.HandleFunc("PUT /api/user/me", app.UserHandler.UpdateMe)
+	protectedMux.HandleFunc("PUT /api/user/password", app.EmailAuthHandler.ChangePassword)
+
+	// Session routes
+	protectedMux.HandleFunc("GET /api/user/sessions", app.SessionHandler.GetSessions)
+	protectedMux.HandleFunc("DELETE /api/user/sessions", app.SessionHandler.RevokeAllSessions)
+	protectedMux.HandleFunc("DELETE /api/user/sessions/{sessionID}", app.SessionHandler.RevokeSession)
+
+	// Identity routes
+	protectedMux.HandleFunc("GET /api/user/identities", app.IdentityHandler.GetIdentities)
+	protectedMux.HandleFunc("POST /api/user/identities/{provider}", app.IdentityHandler.StartLinkIdentity)
+	protectedMux.HandleFunc("DELETE /api/user/identities/{identityID}", app.IdentityHandler.UnlinkIdentity)
 
 	// Habit routes
 	protectedMux.HandleFunc("GET /api/habits", app.HabitHandler.GetHabitsByUserID)
 	protectedMux.HandleFunc("POST /api/habits", app.HabitHandler.CreateHabit)
+	protectedMux.HandleFunc("GET /api/habits/due", app.HabitHandler.GetDueHabits)
 	protectedMux.HandleFunc("GET /api/habits/{habitID}", app.HabitHandler.GetHabit)
 	protectedMux.HandleFunc("PUT /api/habits/{habitID}", app.HabitHandler.UpdateHabit)
 	protectedMux.HandleFunc("DELETE /api/habits/{habitID}", app.HabitHandler.DeleteHabit)
+	protectedMux.HandleFunc("GET /api/habits/{habitID}/freezes", app.HabitHandler.GetStreakFreezes)
 
 	// Completion routes
 	protectedMux.HandleFunc("GET /api/completions", app.CompletionHandler.GetCompletions)
@@ -36,13 +60,43 @@ func SetupRoutes(app *app.Application) http.Handler {
 	protectedMux.HandleFunc("GET /api/completions/{completionID}", app.CompletionHandler.GetCompletion)
 	protectedMux.HandleFunc("PUT /api/completions/{completionID}", app.CompletionHandler.UpdateCompletion)
 	protectedMux.HandleFunc("DELETE /api/completions/{completionID}", app.CompletionHandler.DeleteCompletion)
+	protectedMux.HandleFunc("POST /api/habits/{habitID}/checkins", app.CompletionHandler.CheckIn)
+	protectedMux.HandleFunc("POST /api/habits/{habitID}/checkins/undo", app.CompletionHandler.UndoCheckIn)
+
+	// Timer routes
+	protectedMux.HandleFunc("GET /api/habits/{habitID}/timer", app.TimerHandler.GetTimer)
+	protectedMux.HandleFunc("POST /api/habits/{habitID}/timer/start", app.TimerHandler.StartTimer)
+	protectedMux.HandleFunc("POST /api/habits/{habitID}/timer/pause", app.TimerHandler.PauseTimer)
+	protectedMux.HandleFunc("POST /api/habits/{habitID}/timer/stop", app.TimerHandler.StopTimer)
+
+	// Time off routes
+	protectedMux.HandleFunc("POST /api/habits/{habitID}/skips", app.TimeOffHandler.CreateSkip)
+	protectedMux.HandleFunc("DELETE /api/skips/{skipID}", app.TimeOffHandler.DeleteSkip)
+	protectedMux.HandleFunc("GET /api/pauses", app.TimeOffHandler.GetPauses)
+	protectedMux.HandleFunc("POST /api/pauses", app.TimeOffHandler.CreatePause)
+	protectedMux.HandleFunc("DELETE /api/pauses/{pauseID}", app.TimeOffHandler.DeletePause)
+
+	// Stats routes
+	protectedMux.HandleFunc("GET /api/stats/heatmap", app.StatsHandler.GetHeatmap)
+	protectedMux.HandleFunc("GET /api/stats/overview", app.StatsHandler.GetOverview)
+	protectedMux.HandleFunc("GET /api/stats/correlations", app.StatsHandler.GetCorrelations)
+	protectedMux.HandleFunc("GET /api/habits/{habitID}/stats", app.StatsHandler.GetHabitStats)
 
 	// Apply auth middleware to protected routes
 	router.Handle("/api/user/me", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/user/password", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/user/sessions", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/user/sessions/", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/user/identities", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/user/identities/", authMiddleware.RequireAuth(protectedMux))
 	router.Handle("/api/habits", authMiddleware.RequireAuth(protectedMux))
 	router.Handle("/api/habits/", authMiddleware.RequireAuth(protectedMux))
 	router.Handle("/api/completions", authMiddleware.RequireAuth(protectedMux))
 	router.Handle("/api/completions/", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/skips/", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/pauses", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/pauses/", authMiddleware.RequireAuth(protectedMux))
+	router.Handle("/api/stats/", authMiddleware.RequireAuth(protectedMux))
 
 	handler := authMiddleware.Logging(router)
 
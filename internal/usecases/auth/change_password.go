@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type ChangePasswordUsecase struct {
+	passwordRepo repository.PasswordRepository
+}
+
+func NewChangePasswordUsecase(passwordRepo repository.PasswordRepository) *ChangePasswordUsecase {
+	return &ChangePasswordUsecase{passwordRepo: passwordRepo}
+}
+
+// Execute sets the user's password. A user who already has one must give it as
+// currentPassword, or entity.ErrInvalidCredentials is returned.
+func (uc *ChangePasswordUsecase) Execute(ctx context.Context, userID, currentPassword, newPassword string) error {
+	existing, err := uc.passwordRepo.FindByUserID(ctx, userID)
+	if err != nil && err != apperrors.ErrNotFound {
+		return err
+	}
+
+	if existing != nil {
+		ok, err := utils.VerifyPassword(currentPassword, existing.PasswordHash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return entity.ErrInvalidCredentials
+		}
+	}
+
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return uc.passwordRepo.Save(ctx, entity.NewUserPassword(userID, passwordHash, time.Now()))
+}
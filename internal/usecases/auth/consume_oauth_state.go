@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type ConsumeOAuthStateUsecase struct {
+	oauthStateRepo repository.OAuthStateRepository
+}
+
+func NewConsumeOAuthStateUsecase(oauthStateRepo repository.OAuthStateRepository) *ConsumeOAuthStateUsecase {
+	return &ConsumeOAuthStateUsecase{oauthStateRepo: oauthStateRepo}
+}
+
+// Execute marks a validated OAuth state as used and reports whether it hadn't been used
+// before, so a callback can't be replayed with a captured state cookie. Used states are
+// kept in the database until they expire, which makes the check hold across restarts
+// and between instances.
+func (uc *ConsumeOAuthStateUsecase) Execute(ctx context.Context, oauthState *utils.OAuthState) (bool, error) {
+	return uc.oauthStateRepo.Use(ctx, oauthState.State, oauthState.ExpiresAt, time.Now())
+}
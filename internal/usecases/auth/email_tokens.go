@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+// Frontend pages the links in emails open, each reading the token from its query string
+const (
+	verifyEmailPath   = "/auth/verify-email"
+	resetPasswordPath = "/auth/reset-password"
+	magicLinkPath     = "/auth/magic-link"
+	loginPath         = "/auth"
+)
+
+// issueEmailToken stores a new token of the purpose for the user and returns the token
+// to put in the email
+func issueEmailToken(ctx context.Context, emailTokenRepo repository.EmailTokenRepository, userID string, purpose entity.EmailTokenPurpose) (string, error) {
+	token, tokenHash, err := utils.GenerateEmailToken()
+	if err != nil {
+		return "", err
+	}
+
+	emailToken := entity.NewEmailToken(uuid.NewString(), userID, purpose, tokenHash, time.Now())
+	if err := emailTokenRepo.Create(ctx, emailToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeEmailToken uses up the token, returning entity.ErrInvalidEmailToken when it
+// can't be used
+func consumeEmailToken(ctx context.Context, emailTokenRepo repository.EmailTokenRepository, token string, purpose entity.EmailTokenPurpose, now time.Time) (*entity.EmailToken, error) {
+	emailToken, err := emailTokenRepo.Consume(ctx, utils.HashEmailToken(token), purpose, now)
+	if err != nil {
+		if err == apperrors.ErrNotFound {
+			return nil, entity.ErrInvalidEmailToken
+		}
+		return nil, err
+	}
+
+	return emailToken, nil
+}
+
+// emailLink returns the link to the frontend page at path, with the token if not empty
+func emailLink(baseURL, path, token string) string {
+	link := strings.TrimSuffix(baseURL, "/") + path
+	if token != "" {
+		link += "?" + url.Values{"token": {token}}.Encode()
+	}
+	return link
+}
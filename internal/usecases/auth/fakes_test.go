@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+// The fakes below stand in for the repositories the usecases of this package use. Each
+// embeds its repository interface and only implements the methods the tests reach, so
+// calling any other panics.
+
+// fakeAccounts holds users with their identities, passwords and whether their sessions
+// were revoked. The user, identity and password fakes share one, the way the real
+// repositories share the database.
+type fakeAccounts struct {
+	users      map[string]*entity.User
+	identities []*entity.UserIdentity
+	passwords  map[string]*entity.UserPassword
+	revoked    map[string]bool
+}
+
+func newFakeAccounts() *fakeAccounts {
+	return &fakeAccounts{
+		users:     map[string]*entity.User{},
+		passwords: map[string]*entity.UserPassword{},
+		revoked:   map[string]bool{},
+	}
+}
+
+func (f *fakeAccounts) addUser(t *testing.T, email string, verified bool) *entity.User {
+	user, err := entity.NewUser("user-"+email, email, "Ada", "")
+	require.NoError(t, err)
+	if verified {
+		user.VerifyEmail(time.Now())
+	}
+	f.users[user.ID] = user
+	return user
+}
+
+func (f *fakeAccounts) userByEmail(email string) (*entity.User, error) {
+	for _, user := range f.users {
+		if user.Email == entity.NormalizeEmail(email) {
+			return user, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+// verifyEmail mirrors the repositories' verifyEmail helper
+func (f *fakeAccounts) verifyEmail(userID, keptPasswordHash string, now time.Time) error {
+	user, ok := f.users[userID]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+	if password, ok := f.passwords[userID]; ok && password.PasswordHash != keptPasswordHash {
+		delete(f.passwords, userID)
+	}
+	f.revoked[userID] = true
+	user.VerifyEmail(now)
+	return nil
+}
+
+type fakeUserRepository struct {
+	repository.UserRepository
+	*fakeAccounts
+}
+
+type fakeIdentityRepository struct {
+	repository.IdentityRepository
+	*fakeAccounts
+}
+
+type fakePasswordRepository struct {
+	repository.PasswordRepository
+	*fakeAccounts
+}
+
+func (f fakeUserRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f fakeUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return f.userByEmail(email)
+}
+
+func (f fakeUserRepository) MarkEmailVerified(ctx context.Context, userID string, now time.Time) error {
+	return f.verifyEmail(userID, "", now)
+}
+
+func (f fakeIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (f fakeIdentityRepository) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	for _, existing := range f.identities {
+		if existing.Provider == identity.Provider && (existing.Subject == identity.Subject || existing.UserID == identity.UserID) {
+			return apperrors.ErrAlreadyExists
+		}
+	}
+	f.identities = append(f.identities, identity)
+	return nil
+}
+
+func (f fakeIdentityRepository) CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error {
+	if _, err := f.userByEmail(user.Email); err == nil {
+		return apperrors.ErrAlreadyExists
+	}
+	f.users[user.ID] = user
+	return f.Create(ctx, identity)
+}
+
+func (f fakeIdentityRepository) CreateVerifyingEmail(ctx context.Context, identity *entity.UserIdentity, now time.Time) error {
+	if _, err := f.FindByProviderSubject(ctx, identity.Provider, identity.Subject); err == nil {
+		return apperrors.ErrAlreadyExists
+	}
+	if err := f.verifyEmail(identity.UserID, "", now); err != nil {
+		return err
+	}
+	return f.Create(ctx, identity)
+}
+
+func (f fakePasswordRepository) FindByUserID(ctx context.Context, userID string) (*entity.UserPassword, error) {
+	password, ok := f.passwords[userID]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return password, nil
+}
+
+func (f fakePasswordRepository) VerifyEmail(ctx context.Context, password *entity.UserPassword, now time.Time) error {
+	return f.verifyEmail(password.UserID, password.PasswordHash, now)
+}
+
+type fakeEmailTokenRepository struct {
+	repository.EmailTokenRepository
+	tokens []*entity.EmailToken
+}
+
+// issue stores a token of the purpose for the user and returns the token to put in the
+// email
+func (f *fakeEmailTokenRepository) issue(t *testing.T, userID string, purpose entity.EmailTokenPurpose) string {
+	token, tokenHash, err := utils.GenerateEmailToken()
+	require.NoError(t, err)
+	f.tokens = append(f.tokens, entity.NewEmailToken("token-"+userID, userID, purpose, tokenHash, time.Now()))
+	return token
+}
+
+func (f *fakeEmailTokenRepository) Consume(ctx context.Context, tokenHash string, purpose entity.EmailTokenPurpose, now time.Time) (*entity.EmailToken, error) {
+	for _, token := range f.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose && token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.UsedAt = &now
+			return token, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+// fakeRefreshTokenRepository answers Rotate with next or err, recording what it was
+// called with
+type fakeRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+	next *entity.RefreshToken
+	err  error
+
+	tokenID   string
+	nextID    string
+	userAgent string
+	ipAddress string
+}
+
+func (f *fakeRefreshTokenRepository) Rotate(ctx context.Context, tokenID, nextID, userAgent, ipAddress string, now time.Time) (*entity.RefreshToken, error) {
+	f.tokenID = tokenID
+	f.nextID = nextID
+	f.userAgent = userAgent
+	f.ipAddress = ipAddress
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.next, nil
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type IssueRefreshTokenUsecase struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewIssueRefreshTokenUsecase(sessionRepo repository.SessionRepository) *IssueRefreshTokenUsecase {
+	return &IssueRefreshTokenUsecase{sessionRepo: sessionRepo}
+}
+
+// Execute starts a session for a login from the given client and returns the first
+// signed refresh token of its family
+func (uc *IssueRefreshTokenUsecase) Execute(ctx context.Context, userID, userAgent, ipAddress string) (string, error) {
+	now := time.Now()
+	session := entity.NewSession(uuid.NewString(), userID, userAgent, ipAddress, now)
+	token := entity.NewRefreshToken(uuid.NewString(), userID, session.ID, now)
+
+	if err := uc.sessionRepo.Create(ctx, session, token); err != nil {
+		return "", err
+	}
+
+	return utils.GenerateRefreshToken(token.UserID, token.ID, token.ExpiresAt)
+}
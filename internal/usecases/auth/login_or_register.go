@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/oidc"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type LoginOrRegisterUserUsecase struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+}
+
+func NewLoginOrRegisterUserUsecase(userRepo repository.UserRepository, identityRepo repository.IdentityRepository) *LoginOrRegisterUserUsecase {
+	return &LoginOrRegisterUserUsecase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+	}
+}
+
+// Execute returns the user who logged in with the provider, registering them on their
+// first login. An identity seen for the first time whose email is verified by a trusted
+// provider and belongs to an existing user is linked to that user; any other returns
+// apperrors.ErrAlreadyExists, and the user has to log in and link it explicitly. When
+// the existing user never verified the email address themselves, their password and
+// sessions are dropped, as whoever signed up with it may not own the address.
+func (uc *LoginOrRegisterUserUsecase) Execute(ctx context.Context, provider string, profile *oidc.Profile) (*entity.User, error) {
+	identity, err := uc.identityRepo.FindByProviderSubject(ctx, provider, profile.Subject)
+	if err == nil {
+		return uc.userRepo.FindByID(ctx, identity.UserID)
+	}
+	if err != apperrors.ErrNotFound {
+		return nil, err
+	}
+
+	if profile.EmailVerified {
+		user, err := uc.userRepo.FindByEmail(ctx, profile.Email)
+		if err == nil {
+			identity, err := entity.NewUserIdentity(uuid.NewString(), user.ID, provider, profile.Subject, profile.Email)
+			if err != nil {
+				return nil, apperrors.ErrInvalidInput
+			}
+			now := time.Now()
+			if err := uc.identityRepo.CreateVerifyingEmail(ctx, identity, now); err != nil {
+				return nil, err
+			}
+			user.VerifyEmail(now)
+			return user, nil
+		}
+		if err != apperrors.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	// User not found, create a new one
+	newUser, err := entity.NewUser(uuid.NewString(), profile.Email, profile.Name, profile.Picture)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+	if profile.EmailVerified {
+		newUser.VerifyEmail(time.Now())
+	}
+
+	identity, err = entity.NewUserIdentity(uuid.NewString(), newUser.ID, provider, profile.Subject, profile.Email)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	if err := uc.identityRepo.CreateWithUser(ctx, newUser, identity); err != nil {
+		return nil, err
+	}
+
+	return newUser, nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/oidc"
+)
+
+func TestLoginOrRegisterUserUsecase(t *testing.T) {
+	tests := []struct {
+		name string
+		// Existing user with the profile's email and whether they verified it
+		existing, existingVerified bool
+		// Whether the identity is already linked to the existing user
+		linked  bool
+		profile oidc.Profile
+		// Whether the login ends up as the existing user
+		wantExisting bool
+		wantVerified bool
+		wantPassword bool
+		wantRevoked  bool
+		wantErr      error
+	}{
+		{
+			name:         "returning identity",
+			existing:     true,
+			linked:       true,
+			profile:      oidc.Profile{Subject: "sub-1", Email: "ada@example.com"},
+			wantExisting: true,
+			wantPassword: true,
+		},
+		{
+			name:         "new user with a verified email",
+			profile:      oidc.Profile{Subject: "sub-1", Email: "Ada@Example.com", EmailVerified: true},
+			wantVerified: true,
+		},
+		{
+			name:    "new user with an unverified email",
+			profile: oidc.Profile{Subject: "sub-1", Email: "ada@example.com"},
+		},
+		{
+			name:             "verified email of a verified user links to them",
+			existing:         true,
+			existingVerified: true,
+			profile:          oidc.Profile{Subject: "sub-1", Email: "ADA@example.com", EmailVerified: true},
+			wantExisting:     true,
+			wantVerified:     true,
+			wantPassword:     true,
+		},
+		{
+			name:         "verified email of an unverified user drops their password and sessions",
+			existing:     true,
+			profile:      oidc.Profile{Subject: "sub-1", Email: "ada@example.com", EmailVerified: true},
+			wantExisting: true,
+			wantVerified: true,
+			wantRevoked:  true,
+		},
+		{
+			name:             "unverified email of an existing user",
+			existing:         true,
+			existingVerified: true,
+			profile:          oidc.Profile{Subject: "sub-1", Email: "ada@example.com"},
+			wantErr:          apperrors.ErrAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := newFakeAccounts()
+			var existing *entity.User
+			if tt.existing {
+				existing = accounts.addUser(t, "ada@example.com", tt.existingVerified)
+				accounts.passwords[existing.ID] = entity.NewUserPassword(existing.ID, "hash", time.Now())
+				if tt.linked {
+					identity, err := entity.NewUserIdentity("identity-1", existing.ID, "acme", tt.profile.Subject, tt.profile.Email)
+					require.NoError(t, err)
+					accounts.identities = append(accounts.identities, identity)
+				}
+			}
+
+			uc := NewLoginOrRegisterUserUsecase(fakeUserRepository{fakeAccounts: accounts}, fakeIdentityRepository{fakeAccounts: accounts})
+			user, err := uc.Execute(context.Background(), "acme", &tt.profile)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Len(t, accounts.identities, 0)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantExisting {
+				assert.Equal(t, existing.ID, user.ID)
+				_, hasPassword := accounts.passwords[existing.ID]
+				assert.Equal(t, tt.wantPassword, hasPassword)
+				assert.Equal(t, tt.wantRevoked, accounts.revoked[existing.ID])
+			} else {
+				assert.NotEqual(t, "user-ada@example.com", user.ID)
+				assert.Equal(t, "ada@example.com", user.Email)
+			}
+			assert.Equal(t, tt.wantVerified, user.IsEmailVerified())
+			assert.Equal(t, tt.wantVerified, accounts.users[user.ID].IsEmailVerified())
+
+			identity, err := (fakeIdentityRepository{fakeAccounts: accounts}).FindByProviderSubject(context.Background(), "acme", tt.profile.Subject)
+			require.NoError(t, err)
+			assert.Equal(t, user.ID, identity.UserID)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type LoginWithMagicLinkUsecase struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+}
+
+func NewLoginWithMagicLinkUsecase(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository) *LoginWithMagicLinkUsecase {
+	return &LoginWithMagicLinkUsecase{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+	}
+}
+
+// Execute returns the user the magic link was sent to. Opening the link proves they own
+// the email address, so it is marked verified, dropping the password and sessions of a
+// user who hadn't verified it as someone else may have set them up.
+func (uc *LoginWithMagicLinkUsecase) Execute(ctx context.Context, token string) (*entity.User, error) {
+	now := time.Now()
+
+	emailToken, err := consumeEmailToken(ctx, uc.emailTokenRepo, token, entity.EmailTokenMagicLink, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.MarkEmailVerified(ctx, emailToken.UserID, now); err != nil {
+		return nil, err
+	}
+
+	return uc.userRepo.FindByID(ctx, emailToken.UserID)
+}
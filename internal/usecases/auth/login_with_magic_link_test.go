@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+)
+
+func TestLoginWithMagicLinkUsecase(t *testing.T) {
+	tests := []struct {
+		name         string
+		verified     bool
+		wantPassword bool
+		wantRevoked  bool
+	}{
+		{
+			name:         "verified user keeps their password and sessions",
+			verified:     true,
+			wantPassword: true,
+		},
+		{
+			name:        "unverified user loses the password and sessions someone may have set up",
+			wantRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := newFakeAccounts()
+			user := accounts.addUser(t, "ada@example.com", tt.verified)
+			accounts.passwords[user.ID] = entity.NewUserPassword(user.ID, "hash", time.Now())
+			emailTokens := &fakeEmailTokenRepository{}
+			token := emailTokens.issue(t, user.ID, entity.EmailTokenMagicLink)
+
+			loggedIn, err := NewLoginWithMagicLinkUsecase(fakeUserRepository{fakeAccounts: accounts}, emailTokens).Execute(context.Background(), token)
+			require.NoError(t, err)
+
+			assert.Equal(t, user.ID, loggedIn.ID)
+			assert.True(t, loggedIn.IsEmailVerified())
+			_, hasPassword := accounts.passwords[user.ID]
+			assert.Equal(t, tt.wantPassword, hasPassword)
+			assert.Equal(t, tt.wantRevoked, accounts.revoked[user.ID])
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"sync"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+// dummyPasswordHash is checked against when there's no password to check, so failed
+// logins take as long whether or not the account exists
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := utils.HashPassword("dummy password")
+	return hash
+})
+
+type LoginWithPasswordUsecase struct {
+	userRepo     repository.UserRepository
+	passwordRepo repository.PasswordRepository
+}
+
+func NewLoginWithPasswordUsecase(userRepo repository.UserRepository, passwordRepo repository.PasswordRepository) *LoginWithPasswordUsecase {
+	return &LoginWithPasswordUsecase{
+		userRepo:     userRepo,
+		passwordRepo: passwordRepo,
+	}
+}
+
+// Execute returns the user with the email address and password. A wrong email address
+// or password returns entity.ErrInvalidCredentials, and a right one of a user who hasn't
+// verified their email address returns entity.ErrEmailNotVerified.
+func (uc *LoginWithPasswordUsecase) Execute(ctx context.Context, email, password string) (*entity.User, error) {
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil && err != apperrors.ErrNotFound {
+		return nil, err
+	}
+
+	passwordHash := dummyPasswordHash()
+	if user != nil {
+		userPassword, err := uc.passwordRepo.FindByUserID(ctx, user.ID)
+		if err != nil && err != apperrors.ErrNotFound {
+			return nil, err
+		}
+		if userPassword != nil {
+			passwordHash = userPassword.PasswordHash
+		} else {
+			user = nil
+		}
+	}
+
+	ok, err := utils.VerifyPassword(password, passwordHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok || user == nil {
+		return nil, entity.ErrInvalidCredentials
+	}
+
+	if !user.IsEmailVerified() {
+		return nil, entity.ErrEmailNotVerified
+	}
+
+	return user, nil
+}
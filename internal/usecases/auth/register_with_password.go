@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/mailer"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type RegisterWithPasswordUsecase struct {
+	userRepo       repository.UserRepository
+	passwordRepo   repository.PasswordRepository
+	emailTokenRepo repository.EmailTokenRepository
+	mailer         mailer.Mailer
+	linkBaseURL    string
+}
+
+func NewRegisterWithPasswordUsecase(userRepo repository.UserRepository, passwordRepo repository.PasswordRepository, emailTokenRepo repository.EmailTokenRepository, mailer mailer.Mailer, linkBaseURL string) *RegisterWithPasswordUsecase {
+	return &RegisterWithPasswordUsecase{
+		userRepo:       userRepo,
+		passwordRepo:   passwordRepo,
+		emailTokenRepo: emailTokenRepo,
+		mailer:         mailer,
+		linkBaseURL:    linkBaseURL,
+	}
+}
+
+// Execute signs up a user with a password and emails them a link to verify their email
+// address, which they need to do before logging in with the password. Signing up with
+// an email address that already has an account emails its owner instead, and looks the
+// same to the caller, so the sign up form can't be used to find out who has an account.
+func (uc *RegisterWithPasswordUsecase) Execute(ctx context.Context, email, password, name string) error {
+	email = strings.TrimSpace(email)
+
+	_, err := uc.userRepo.FindByEmail(ctx, email)
+	if err == nil {
+		return uc.mailer.Send(ctx, mailer.AccountExistsMessage(email, emailLink(uc.linkBaseURL, loginPath, "")))
+	}
+	if err != apperrors.ErrNotFound {
+		return err
+	}
+
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user, err := entity.NewUser(uuid.NewString(), email, strings.TrimSpace(name), "")
+	if err != nil {
+		return apperrors.ErrInvalidInput
+	}
+
+	err = uc.passwordRepo.CreateWithUser(ctx, user, entity.NewUserPassword(user.ID, passwordHash, time.Now()))
+	if err == apperrors.ErrAlreadyExists {
+		return uc.mailer.Send(ctx, mailer.AccountExistsMessage(email, emailLink(uc.linkBaseURL, loginPath, "")))
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := issueEmailToken(ctx, uc.emailTokenRepo, user.ID, entity.EmailTokenVerification)
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.Send(ctx, mailer.VerificationMessage(user.Email, emailLink(uc.linkBaseURL, verifyEmailPath, token)))
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/mailer"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type RequestMagicLinkUsecase struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+	mailer         mailer.Mailer
+	linkBaseURL    string
+}
+
+func NewRequestMagicLinkUsecase(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository, mailer mailer.Mailer, linkBaseURL string) *RequestMagicLinkUsecase {
+	return &RequestMagicLinkUsecase{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		mailer:         mailer,
+		linkBaseURL:    linkBaseURL,
+	}
+}
+
+// Execute emails a link that logs in without a password to the email address if it
+// belongs to a user, and silently does nothing otherwise
+func (uc *RequestMagicLinkUsecase) Execute(ctx context.Context, email string) error {
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == apperrors.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := issueEmailToken(ctx, uc.emailTokenRepo, user.ID, entity.EmailTokenMagicLink)
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.Send(ctx, mailer.MagicLinkMessage(user.Email, emailLink(uc.linkBaseURL, magicLinkPath, token)))
+}
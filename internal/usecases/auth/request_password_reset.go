@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/mailer"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type RequestPasswordResetUsecase struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+	mailer         mailer.Mailer
+	linkBaseURL    string
+}
+
+func NewRequestPasswordResetUsecase(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository, mailer mailer.Mailer, linkBaseURL string) *RequestPasswordResetUsecase {
+	return &RequestPasswordResetUsecase{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		mailer:         mailer,
+		linkBaseURL:    linkBaseURL,
+	}
+}
+
+// Execute emails a password reset link to the email address if it belongs to a user,
+// and silently does nothing otherwise. Users who only log in with identity providers
+// can use it to set a password.
+func (uc *RequestPasswordResetUsecase) Execute(ctx context.Context, email string) error {
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == apperrors.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := issueEmailToken(ctx, uc.emailTokenRepo, user.ID, entity.EmailTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.Send(ctx, mailer.PasswordResetMessage(user.Email, emailLink(uc.linkBaseURL, resetPasswordPath, token)))
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type ResetPasswordUsecase struct {
+	passwordRepo   repository.PasswordRepository
+	emailTokenRepo repository.EmailTokenRepository
+}
+
+func NewResetPasswordUsecase(passwordRepo repository.PasswordRepository, emailTokenRepo repository.EmailTokenRepository) *ResetPasswordUsecase {
+	return &ResetPasswordUsecase{
+		passwordRepo:   passwordRepo,
+		emailTokenRepo: emailTokenRepo,
+	}
+}
+
+// Execute sets the password of the user the reset token was sent to. Opening the link
+// proves they own the email address, so it is marked verified. Every session of the
+// user is revoked, in case the reset is because someone else got in.
+func (uc *ResetPasswordUsecase) Execute(ctx context.Context, token, password string) error {
+	now := time.Now()
+
+	emailToken, err := consumeEmailToken(ctx, uc.emailTokenRepo, token, entity.EmailTokenPasswordReset, now)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return uc.passwordRepo.Reset(ctx, entity.NewUserPassword(emailToken.UserID, passwordHash, now), now)
+}
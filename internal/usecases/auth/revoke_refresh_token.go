@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type RevokeRefreshTokenUsecase struct {
+	refreshTokenRepo repository.RefreshTokenRepository
+}
+
+func NewRevokeRefreshTokenUsecase(refreshTokenRepo repository.RefreshTokenRepository) *RevokeRefreshTokenUsecase {
+	return &RevokeRefreshTokenUsecase{refreshTokenRepo: refreshTokenRepo}
+}
+
+// Execute revokes the family of a signed refresh token, ending the login it came from.
+// Tokens that are invalid or unknown have nothing left to revoke.
+func (uc *RevokeRefreshTokenUsecase) Execute(ctx context.Context, refreshToken string) error {
+	_, tokenID, err := utils.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil
+	}
+
+	err = uc.refreshTokenRepo.RevokeFamily(ctx, tokenID, time.Now())
+	if err != nil && err != apperrors.ErrNotFound {
+		return err
+	}
+
+	return nil
+}
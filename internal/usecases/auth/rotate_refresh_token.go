@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type RotateRefreshTokenUsecase struct {
+	refreshTokenRepo repository.RefreshTokenRepository
+}
+
+func NewRotateRefreshTokenUsecase(refreshTokenRepo repository.RefreshTokenRepository) *RotateRefreshTokenUsecase {
+	return &RotateRefreshTokenUsecase{refreshTokenRepo: refreshTokenRepo}
+}
+
+// Execute exchanges a signed refresh token used by the given client for the next one of
+// its family. It returns the new token's record, whose FamilyID is the session, along
+// with the signed token. Presenting a token that was already used revokes the family
+// and returns entity.ErrRefreshTokenReused; any other rejected token returns
+// apperrors.ErrUnauthorized.
+func (uc *RotateRefreshTokenUsecase) Execute(ctx context.Context, refreshToken, userAgent, ipAddress string) (*entity.RefreshToken, string, error) {
+	_, tokenID, err := utils.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", apperrors.ErrUnauthorized
+	}
+
+	next, err := uc.refreshTokenRepo.Rotate(ctx, tokenID, uuid.NewString(), userAgent, ipAddress, time.Now())
+	if err != nil {
+		if err == apperrors.ErrNotFound {
+			return nil, "", apperrors.ErrUnauthorized
+		}
+		return nil, "", err
+	}
+
+	nextToken, err := utils.GenerateRefreshToken(next.UserID, next.ID, next.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return next, nextToken, nil
+}
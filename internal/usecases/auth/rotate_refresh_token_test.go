@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+const testRefreshSecret = "refresh-secret-of-at-least-32-characters"
+
+func TestRotateRefreshTokenUsecase(t *testing.T) {
+	t.Setenv("JWT_REFRESH_SECRET", testRefreshSecret)
+
+	presented := entity.NewRefreshToken("token-1", "user-1", "session-1", time.Now())
+	next := presented.Rotate("token-2", time.Now())
+	errConnection := errors.New("connection reset")
+
+	sign := func(secret string) string {
+		t.Setenv("JWT_REFRESH_SECRET", secret)
+		defer t.Setenv("JWT_REFRESH_SECRET", testRefreshSecret)
+		signed, err := utils.GenerateRefreshToken(presented.UserID, presented.ID, presented.ExpiresAt)
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		// Rotate's answer
+		rotateErr error
+		// Whether the token got to the repository
+		wantRotated bool
+		wantErr     error
+	}{
+		{
+			name:        "rotated token",
+			token:       sign(testRefreshSecret),
+			wantRotated: true,
+		},
+		{
+			name:    "malformed token",
+			token:   "not-a-token",
+			wantErr: apperrors.ErrUnauthorized,
+		},
+		{
+			name:    "token signed with another secret",
+			token:   sign("another-secret-of-at-least-32-characters"),
+			wantErr: apperrors.ErrUnauthorized,
+		},
+		{
+			name:        "unknown token",
+			token:       sign(testRefreshSecret),
+			rotateErr:   apperrors.ErrNotFound,
+			wantRotated: true,
+			wantErr:     apperrors.ErrUnauthorized,
+		},
+		{
+			name:        "reused token",
+			token:       sign(testRefreshSecret),
+			rotateErr:   entity.ErrRefreshTokenReused,
+			wantRotated: true,
+			wantErr:     entity.ErrRefreshTokenReused,
+		},
+		{
+			name:        "revoked or expired token",
+			token:       sign(testRefreshSecret),
+			rotateErr:   apperrors.ErrUnauthorized,
+			wantRotated: true,
+			wantErr:     apperrors.ErrUnauthorized,
+		},
+		{
+			name:        "repository failure",
+			token:       sign(testRefreshSecret),
+			rotateErr:   errConnection,
+			wantRotated: true,
+			wantErr:     errConnection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRefreshTokenRepository{next: next, err: tt.rotateErr}
+
+			rotated, nextToken, err := NewRotateRefreshTokenUsecase(repo).Execute(context.Background(), tt.token, "agent", "127.0.0.1")
+
+			if tt.wantRotated {
+				assert.Equal(t, presented.ID, repo.tokenID)
+				assert.NotEmpty(t, repo.nextID)
+				assert.NotEqual(t, presented.ID, repo.nextID)
+				assert.Equal(t, "agent", repo.userAgent)
+				assert.Equal(t, "127.0.0.1", repo.ipAddress)
+			} else {
+				assert.Empty(t, repo.tokenID)
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, rotated)
+				assert.Empty(t, nextToken)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, next, rotated)
+			tokenUserID, tokenID, err := utils.ParseRefreshToken(nextToken)
+			require.NoError(t, err)
+			assert.Equal(t, next.UserID, tokenUserID)
+			assert.Equal(t, next.ID, tokenID)
+		})
+	}
+}
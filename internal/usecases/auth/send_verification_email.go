@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/mailer"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type SendVerificationEmailUsecase struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+	mailer         mailer.Mailer
+	linkBaseURL    string
+}
+
+func NewSendVerificationEmailUsecase(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository, mailer mailer.Mailer, linkBaseURL string) *SendVerificationEmailUsecase {
+	return &SendVerificationEmailUsecase{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		mailer:         mailer,
+		linkBaseURL:    linkBaseURL,
+	}
+}
+
+// Execute sends a new verification link to the email address if it belongs to a user
+// who hasn't verified it yet, and silently does nothing otherwise
+func (uc *SendVerificationEmailUsecase) Execute(ctx context.Context, email string) error {
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == apperrors.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	token, err := issueEmailToken(ctx, uc.emailTokenRepo, user.ID, entity.EmailTokenVerification)
+	if err != nil {
+		return err
+	}
+
+	return uc.mailer.Send(ctx, mailer.VerificationMessage(user.Email, emailLink(uc.linkBaseURL, verifyEmailPath, token)))
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+type VerifyEmailUsecase struct {
+	userRepo       repository.UserRepository
+	passwordRepo   repository.PasswordRepository
+	emailTokenRepo repository.EmailTokenRepository
+}
+
+func NewVerifyEmailUsecase(userRepo repository.UserRepository, passwordRepo repository.PasswordRepository, emailTokenRepo repository.EmailTokenRepository) *VerifyEmailUsecase {
+	return &VerifyEmailUsecase{
+		userRepo:       userRepo,
+		passwordRepo:   passwordRepo,
+		emailTokenRepo: emailTokenRepo,
+	}
+}
+
+// Execute marks the email address the verification token was sent to as verified. A
+// user who has a password must give it, so the owner of the address can't confirm a
+// password someone else signed up with; a wrong one returns
+// entity.ErrInvalidCredentials, and the token is used up either way so it can't be used
+// to guess the password.
+func (uc *VerifyEmailUsecase) Execute(ctx context.Context, token, password string) error {
+	now := time.Now()
+
+	emailToken, err := consumeEmailToken(ctx, uc.emailTokenRepo, token, entity.EmailTokenVerification, now)
+	if err != nil {
+		return err
+	}
+
+	userPassword, err := uc.passwordRepo.FindByUserID(ctx, emailToken.UserID)
+	if err == apperrors.ErrNotFound {
+		return uc.userRepo.MarkEmailVerified(ctx, emailToken.UserID, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	ok, err := utils.VerifyPassword(password, userPassword.PasswordHash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return entity.ErrInvalidCredentials
+	}
+
+	return uc.passwordRepo.VerifyEmail(ctx, userPassword, now)
+}
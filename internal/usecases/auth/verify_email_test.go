@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/utils"
+)
+
+func TestVerifyEmailUsecase(t *testing.T) {
+	tests := []struct {
+		name string
+		// Password the user signed up with, none when empty
+		signedUpWith string
+		password     string
+		purpose      entity.EmailTokenPurpose
+		wantPassword bool
+		wantErr      error
+	}{
+		{
+			name:    "user without a password",
+			purpose: entity.EmailTokenVerification,
+		},
+		{
+			name:         "user giving their password",
+			signedUpWith: "correct horse",
+			password:     "correct horse",
+			purpose:      entity.EmailTokenVerification,
+			wantPassword: true,
+		},
+		{
+			name:         "user giving a wrong password",
+			signedUpWith: "correct horse",
+			password:     "battery staple",
+			purpose:      entity.EmailTokenVerification,
+			wantPassword: true,
+			wantErr:      entity.ErrInvalidCredentials,
+		},
+		{
+			name:         "user giving no password",
+			signedUpWith: "correct horse",
+			purpose:      entity.EmailTokenVerification,
+			wantPassword: true,
+			wantErr:      entity.ErrInvalidCredentials,
+		},
+		{
+			name:    "token of another purpose",
+			purpose: entity.EmailTokenPasswordReset,
+			wantErr: entity.ErrInvalidEmailToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := newFakeAccounts()
+			user := accounts.addUser(t, "ada@example.com", false)
+			if tt.signedUpWith != "" {
+				passwordHash, err := utils.HashPassword(tt.signedUpWith)
+				require.NoError(t, err)
+				accounts.passwords[user.ID] = entity.NewUserPassword(user.ID, passwordHash, time.Now())
+			}
+			emailTokens := &fakeEmailTokenRepository{}
+			token := emailTokens.issue(t, user.ID, tt.purpose)
+
+			uc := NewVerifyEmailUsecase(fakeUserRepository{fakeAccounts: accounts}, fakePasswordRepository{fakeAccounts: accounts}, emailTokens)
+			err := uc.Execute(context.Background(), token, tt.password)
+
+			_, hasPassword := accounts.passwords[user.ID]
+			assert.Equal(t, tt.wantPassword, hasPassword)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, user.IsEmailVerified())
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, user.IsEmailVerified())
+			assert.True(t, accounts.revoked[user.ID])
+
+			err = uc.Execute(context.Background(), token, tt.password)
+			assert.ErrorIs(t, err, entity.ErrInvalidEmailToken)
+		})
+	}
+}
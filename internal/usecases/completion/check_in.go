@@ -0,0 +1,82 @@
+package completion
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type CheckInUsecase struct {
+	completionRepo repository.CompletionRepository
+	habitRepo      repository.HabitRepository
+	userRepo       repository.UserRepository
+}
+
+func NewCheckInUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *CheckInUsecase {
+	return &CheckInUsecase{
+		completionRepo: completionRepo,
+		habitRepo:      habitRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// Execute adds the check-in amount to the day's completion, creating it on the first check-in
+func (uc *CheckInUsecase) Execute(ctx context.Context, habitID, userID string, req dto.CheckinDTO) (*entity.HabitCompletion, error) {
+	habit, err := uc.habitRepo.FindByID(ctx, habitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if habit.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := user.Today(time.Now())
+	date, err := resolveCompletionDate(req.Date, today)
+	if err != nil {
+		return nil, err
+	}
+
+	completion, err := entity.NewHabitCompletion(uuid.New().String(), habitID, userID, date, checkinAmount(req), req.Value, habit.TargetCount, habit.TargetValue, nil)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	return uc.completionRepo.Increment(ctx, completion, habit, today)
+}
+
+// resolveCompletionDate parses a YYYY-MM-DD date, defaulting to the user's local today.
+// Days that haven't started yet in the user's timezone are rejected.
+func resolveCompletionDate(date string, today time.Time) (time.Time, error) {
+	if date == "" {
+		return today, nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, apperrors.ErrInvalidInput
+	}
+
+	if parsed.After(today) {
+		return time.Time{}, apperrors.ErrInvalidInput
+	}
+
+	return parsed, nil
+}
+
+func checkinAmount(req dto.CheckinDTO) int {
+	if req.Amount <= 0 {
+		return 1
+	}
+	return req.Amount
+}
@@ -14,12 +14,14 @@ import (
 type CreateCompletionUsecase struct {
 	completionRepo repository.CompletionRepository
 	habitRepo      repository.HabitRepository
+	userRepo       repository.UserRepository
 }
 
-func NewCreateCompletionUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository) *CreateCompletionUsecase {
+func NewCreateCompletionUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *CreateCompletionUsecase {
 	return &CreateCompletionUsecase{
 		completionRepo: completionRepo,
 		habitRepo:      habitRepo,
+		userRepo:       userRepo,
 	}
 }
 
@@ -33,9 +35,15 @@ func (uc *CreateCompletionUsecase) Execute(ctx context.Context, habitID, userID
 		return nil, apperrors.ErrForbidden
 	}
 
-	completionDate, err := time.Parse("2006-01-02", req.CompletionDate)
+	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return nil, apperrors.ErrInvalidInput
+		return nil, err
+	}
+
+	today := user.Today(time.Now())
+	completionDate, err := resolveCompletionDate(req.CompletionDate, today)
+	if err != nil {
+		return nil, err
 	}
 
 	existingCompletion, err := uc.completionRepo.FindByHabitIDAndDate(ctx, habitID, completionDate)
@@ -47,24 +55,16 @@ func (uc *CreateCompletionUsecase) Execute(ctx context.Context, habitID, userID
 		return nil, apperrors.ErrAlreadyExists
 	}
 
+	count := req.Count
+	if count == 0 {
+		count = 1
+	}
+
 	completionID := uuid.New().String()
-	completion, err := entity.NewHabitCompletion(completionID, habitID, userID, completionDate, req.Count, req.Notes)
+	completion, err := entity.NewHabitCompletion(completionID, habitID, userID, completionDate, count, req.Value, habit.TargetCount, habit.TargetValue, req.Notes)
 	if err != nil {
 		return nil, apperrors.ErrInvalidInput
 	}
 
-	habit.IncrementCompletions()
-	if shouldIncrementStreak(completionDate) {
-		habit.IncrementStreak()
-	}
-
-	return uc.completionRepo.Create(ctx, completion, habit)
-}
-
-func shouldIncrementStreak(completionDate time.Time) bool {
-	today := time.Now().Truncate(24 * time.Hour)
-	yesterday := today.AddDate(0, 0, -1)
-	completionDay := completionDate.Truncate(24 * time.Hour)
-
-	return completionDay.Equal(today) || completionDay.Equal(yesterday)
+	return uc.completionRepo.Create(ctx, completion, habit, today)
 }
@@ -2,6 +2,7 @@ package completion
 
 import (
 	"context"
+	"time"
 
 	"github.com/uygardeniz/habit-tracker/internal/apperrors"
 	"github.com/uygardeniz/habit-tracker/internal/repository"
@@ -10,12 +11,14 @@ import (
 type DeleteCompletionUsecase struct {
 	completionRepo repository.CompletionRepository
 	habitRepo      repository.HabitRepository
+	userRepo       repository.UserRepository
 }
 
-func NewDeleteCompletionUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository) *DeleteCompletionUsecase {
+func NewDeleteCompletionUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *DeleteCompletionUsecase {
 	return &DeleteCompletionUsecase{
 		completionRepo: completionRepo,
 		habitRepo:      habitRepo,
+		userRepo:       userRepo,
 	}
 }
 
@@ -34,9 +37,10 @@ func (uc *DeleteCompletionUsecase) Execute(ctx context.Context, completionID, us
 		return err
 	}
 
-	if habit.TotalCompletions > 0 {
-		habit.TotalCompletions--
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
 	}
 
-	return uc.completionRepo.Delete(ctx, completionID, habit)
+	return uc.completionRepo.Delete(ctx, completionID, habit, user.Today(time.Now()))
 }
@@ -11,22 +11,26 @@ import (
 
 type GetCompletionsUsecase struct {
 	completionRepo repository.CompletionRepository
+	timeOffRepo    repository.TimeOffRepository
 }
 
-func NewGetCompletionsUsecase(completionRepo repository.CompletionRepository) *GetCompletionsUsecase {
+func NewGetCompletionsUsecase(completionRepo repository.CompletionRepository, timeOffRepo repository.TimeOffRepository) *GetCompletionsUsecase {
 	return &GetCompletionsUsecase{
 		completionRepo: completionRepo,
+		timeOffRepo:    timeOffRepo,
 	}
 }
 
-func (uc *GetCompletionsUsecase) Execute(ctx context.Context, userID string, query dto.GetCompletionsQueryDTO) ([]*entity.HabitCompletion, error) {
+// Execute returns a page of the user's completions together with the skips and pauses
+// in the same date range. Days off aren't paginated.
+func (uc *GetCompletionsUsecase) Execute(ctx context.Context, userID string, query dto.GetCompletionsQueryDTO) ([]*entity.HabitCompletion, *entity.TimeOff, error) {
 	var startDate, endDate *time.Time
 	var err error
 
 	if query.StartDate != nil && *query.StartDate != "" {
 		parsedStartDate, err := time.Parse("2006-01-02", *query.StartDate)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		startDate = &parsedStartDate
 	}
@@ -34,7 +38,7 @@ func (uc *GetCompletionsUsecase) Execute(ctx context.Context, userID string, que
 	if query.EndDate != nil && *query.EndDate != "" {
 		parsedEndDate, err := time.Parse("2006-01-02", *query.EndDate)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		endDate = &parsedEndDate
 	}
@@ -51,8 +55,13 @@ func (uc *GetCompletionsUsecase) Execute(ctx context.Context, userID string, que
 
 	completions, err := uc.completionRepo.FindByUserID(ctx, userID, query.HabitID, startDate, endDate, limit, offset)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return completions, nil
+	timeOff, err := uc.timeOffRepo.FindByUserID(ctx, userID, query.HabitID, startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return completions, timeOff, nil
 }
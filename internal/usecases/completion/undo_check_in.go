@@ -0,0 +1,56 @@
+package completion
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type UndoCheckInUsecase struct {
+	completionRepo repository.CompletionRepository
+	habitRepo      repository.HabitRepository
+	userRepo       repository.UserRepository
+}
+
+func NewUndoCheckInUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *UndoCheckInUsecase {
+	return &UndoCheckInUsecase{
+		completionRepo: completionRepo,
+		habitRepo:      habitRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// Execute takes the check-in amount back from the day's completion. It returns nil when
+// the completion was removed because its count dropped to zero.
+func (uc *UndoCheckInUsecase) Execute(ctx context.Context, habitID, userID string, req dto.CheckinDTO) (*entity.HabitCompletion, error) {
+	habit, err := uc.habitRepo.FindByID(ctx, habitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if habit.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+
+	// Measurable habits take back a value, count habits only a count
+	if habit.IsMeasurable() != (req.Value != nil) {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := user.Today(time.Now())
+	date, err := resolveCompletionDate(req.Date, today)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.completionRepo.Decrement(ctx, habit, date, checkinAmount(req), req.Value, today)
+}
@@ -2,6 +2,7 @@ package completion
 
 import (
 	"context"
+	"time"
 
 	"github.com/uygardeniz/habit-tracker/internal/apperrors"
 	"github.com/uygardeniz/habit-tracker/internal/dto"
@@ -12,12 +13,14 @@ import (
 type UpdateCompletionUsecase struct {
 	completionRepo repository.CompletionRepository
 	habitRepo      repository.HabitRepository
+	userRepo       repository.UserRepository
 }
 
-func NewUpdateCompletionUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository) *UpdateCompletionUsecase {
+func NewUpdateCompletionUsecase(completionRepo repository.CompletionRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *UpdateCompletionUsecase {
 	return &UpdateCompletionUsecase{
 		completionRepo: completionRepo,
 		habitRepo:      habitRepo,
+		userRepo:       userRepo,
 	}
 }
 
@@ -31,12 +34,14 @@ func (uc *UpdateCompletionUsecase) Execute(ctx context.Context, completionID, us
 		return nil, apperrors.ErrForbidden
 	}
 
-	originalCount := completion.Count
-
 	if req.Count != nil {
 		completion.Count = *req.Count
 	}
 
+	if req.Value != nil {
+		completion.Value = req.Value
+	}
+
 	if req.Notes != nil {
 		completion.SetNotes(*req.Notes)
 	}
@@ -50,9 +55,12 @@ func (uc *UpdateCompletionUsecase) Execute(ctx context.Context, completionID, us
 		return nil, err
 	}
 
-	habit.TotalCompletions = habit.TotalCompletions - originalCount + completion.Count
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	err = uc.completionRepo.Update(ctx, completion, habit)
+	err = uc.completionRepo.Update(ctx, completion, habit, user.Today(time.Now()))
 	if err != nil {
 		return nil, err
 	}
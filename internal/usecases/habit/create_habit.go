@@ -2,6 +2,7 @@ package habit
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/uygardeniz/habit-tracker/internal/apperrors"
@@ -12,10 +13,14 @@ import (
 
 type CreateHabitUsecase struct {
 	habitRepository repository.HabitRepository
+	userRepository  repository.UserRepository
 }
 
-func NewCreateHabitUsecase(habitRepository repository.HabitRepository) *CreateHabitUsecase {
-	return &CreateHabitUsecase{habitRepository: habitRepository}
+func NewCreateHabitUsecase(habitRepository repository.HabitRepository, userRepository repository.UserRepository) *CreateHabitUsecase {
+	return &CreateHabitUsecase{
+		habitRepository: habitRepository,
+		userRepository:  userRepository,
+	}
 }
 
 func (uc *CreateHabitUsecase) Execute(ctx context.Context, userID string, req dto.CreateHabitDTO) (*entity.Habit, error) {
@@ -24,13 +29,45 @@ func (uc *CreateHabitUsecase) Execute(ctx context.Context, userID string, req dt
 		return nil, apperrors.ErrInvalidInput
 	}
 
-	habit, err := entity.NewHabit(uuid.New().String(), userID, req.Name, req.Frequency,
-		req.TargetCount, req.Description, req.Motivation, req.Category, targetDays, req.Color)
+	anchorDate, err := dto.ParseDate(req.AnchorDate)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	// Measurable habits are fulfilled by their target value, so the count target is optional
+	targetCount := req.TargetCount
+	if targetCount == 0 {
+		targetCount = 1
+	}
+
+	schedule := entity.Schedule{
+		Frequency:    req.Frequency,
+		TargetCount:  targetCount,
+		TargetDays:   targetDays,
+		PeriodTarget: req.PeriodTarget,
+		IntervalDays: req.IntervalDays,
+		AnchorDate:   anchorDate,
+		Recurrence:   req.Recurrence,
+		TargetValue:  req.TargetValue,
+	}
+
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := user.Today(time.Now())
+
+	habit, err := entity.NewHabit(uuid.New().String(), userID, req.Name, req.Kind, schedule,
+		req.Description, req.Motivation, req.Category, req.Unit, req.Color, today)
 
 	if err != nil {
 		return nil, apperrors.ErrInvalidInput
 	}
 
+	// The first schedule is in force from the day the habit was created in the user's
+	// timezone, like later revisions and the backfilled ones
+	habit.ReviseSchedule(uuid.New().String(), today)
+
 	habit, err = uc.habitRepository.Create(ctx, habit)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,75 @@
+package habit
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type GetDueHabitsUsecase struct {
+	habitRepository      repository.HabitRepository
+	completionRepository repository.CompletionRepository
+	timeOffRepository    repository.TimeOffRepository
+	userRepository       repository.UserRepository
+}
+
+func NewGetDueHabitsUsecase(habitRepository repository.HabitRepository, completionRepository repository.CompletionRepository, timeOffRepository repository.TimeOffRepository, userRepository repository.UserRepository) *GetDueHabitsUsecase {
+	return &GetDueHabitsUsecase{
+		habitRepository:      habitRepository,
+		completionRepository: completionRepository,
+		timeOffRepository:    timeOffRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// Execute returns the user's active habits that are due on the given YYYY-MM-DD date,
+// or on the user's local today when date is empty
+func (uc *GetDueHabitsUsecase) Execute(ctx context.Context, userID, date string) ([]*entity.DueHabit, error) {
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	day := user.Today(time.Now())
+	if date != "" {
+		day, err = time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+
+	habits, err := uc.habitRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quota habits need the period leading up to the day to know whether they are still due
+	from := day
+	for _, habit := range habits {
+		if periodStart := habit.ScheduleOn(day).PeriodStart(day); periodStart.Before(from) {
+			from = periodStart
+		}
+	}
+
+	completions, err := uc.completionRepository.FindAllByUserIDInRange(ctx, userID, from, day)
+	if err != nil {
+		return nil, err
+	}
+
+	timeOff, err := uc.timeOffRepository.FindByUserID(ctx, userID, nil, &from, &day)
+	if err != nil {
+		return nil, err
+	}
+
+	dueHabits := []*entity.DueHabit{}
+	for _, habit := range habits {
+		if due, ok := habit.DueOn(day, completions, timeOff); ok {
+			dueHabits = append(dueHabits, due)
+		}
+	}
+
+	return dueHabits, nil
+}
@@ -0,0 +1,56 @@
+package habit
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type GetStreakFreezesUsecase struct {
+	habitRepository      repository.HabitRepository
+	completionRepository repository.CompletionRepository
+	timeOffRepository    repository.TimeOffRepository
+	userRepository       repository.UserRepository
+}
+
+func NewGetStreakFreezesUsecase(habitRepository repository.HabitRepository, completionRepository repository.CompletionRepository, timeOffRepository repository.TimeOffRepository, userRepository repository.UserRepository) *GetStreakFreezesUsecase {
+	return &GetStreakFreezesUsecase{
+		habitRepository:      habitRepository,
+		completionRepository: completionRepository,
+		timeOffRepository:    timeOffRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// Execute returns the habit's streak freeze token balance and history as of the user's
+// local today
+func (uc *GetStreakFreezesUsecase) Execute(ctx context.Context, habitID, userID string) (*entity.FreezeLedger, error) {
+	habit, err := uc.habitRepository.FindByID(ctx, habitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if habit.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	completions, err := uc.completionRepository.FindAllByHabitID(ctx, habit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeOff, err := uc.timeOffRepository.FindByUserID(ctx, userID, &habit.ID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return habit.Freezes(completions, timeOff, user.Today(time.Now())), nil
+}
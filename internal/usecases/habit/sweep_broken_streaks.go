@@ -0,0 +1,131 @@
+package habit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type SweepBrokenStreaksUsecase struct {
+	habitRepository       repository.HabitRepository
+	completionRepository  repository.CompletionRepository
+	timeOffRepository     repository.TimeOffRepository
+	streakSweepRepository repository.StreakSweepRepository
+	userRepository        repository.UserRepository
+}
+
+func NewSweepBrokenStreaksUsecase(habitRepository repository.HabitRepository, completionRepository repository.CompletionRepository, timeOffRepository repository.TimeOffRepository, streakSweepRepository repository.StreakSweepRepository, userRepository repository.UserRepository) *SweepBrokenStreaksUsecase {
+	return &SweepBrokenStreaksUsecase{
+		habitRepository:       habitRepository,
+		completionRepository:  completionRepository,
+		timeOffRepository:     timeOffRepository,
+		streakSweepRepository: streakSweepRepository,
+		userRepository:        userRepository,
+	}
+}
+
+// Execute resets the current streak of every habit whose last scheduled day passed
+// without a completion, a streak freeze, a skip or a pause to cover it, brings the
+// clean streaks of quit habits up to the new day, and returns the number of streaks
+// that were reset. Each user is swept at most once per local day and an interrupted
+// sweep resumes on the next call; a failing user is reported without holding up the rest.
+func (uc *SweepBrokenStreaksUsecase) Execute(ctx context.Context, now time.Time) (int, error) {
+	userIDs, err := uc.streakSweepRepository.FindPendingUserIDs(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	resets := 0
+	var errs []error
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return resets, errors.Join(append(errs, err)...)
+		}
+
+		user, err := uc.userRepository.FindByID(ctx, userID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sweep user %s: %w", userID, err))
+			continue
+		}
+
+		count, err := uc.sweepUser(ctx, userID, user.Today(now))
+		resets += count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sweep user %s: %w", userID, err))
+		}
+	}
+
+	return resets, errors.Join(errs...)
+}
+
+func (uc *SweepBrokenStreaksUsecase) sweepUser(ctx context.Context, userID string, sweepDate time.Time) (int, error) {
+	if err := uc.streakSweepRepository.StartRun(ctx, userID, sweepDate); err != nil {
+		return 0, err
+	}
+
+	habits, err := uc.habitRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	timeOff, err := uc.timeOffRepository.FindByUserID(ctx, userID, nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resets := 0
+	for _, habit := range habits {
+		if !habit.IsActive {
+			continue
+		}
+
+		// A quit habit's streak grows with every clean day, so it is brought up to date instead
+		if habit.IsQuit() {
+			if err := uc.completionRepository.RecalculateHabitStats(ctx, habit, sweepDate); err != nil {
+				return resets, err
+			}
+			continue
+		}
+
+		if habit.CurrentStreak == 0 {
+			continue
+		}
+
+		history, err := uc.completionRepository.FindAllByHabitID(ctx, habit.ID)
+		if err != nil {
+			return resets, err
+		}
+
+		if current, _ := habit.CalculateStreaks(history, timeOff, sweepDate); current > 0 {
+			continue
+		}
+
+		reset := &entity.StreakReset{
+			UserID:         userID,
+			SweepDate:      sweepDate,
+			HabitID:        habit.ID,
+			PreviousStreak: habit.CurrentStreak,
+			ResetAt:        time.Now(),
+		}
+
+		applied, err := uc.streakSweepRepository.ApplyReset(ctx, reset)
+		if err != nil {
+			return resets, err
+		}
+
+		if applied {
+			habit.ResetStreak()
+			resets++
+		}
+	}
+
+	if err := uc.streakSweepRepository.CompleteRun(ctx, userID, sweepDate); err != nil {
+		return resets, err
+	}
+
+	return resets, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/uygardeniz/habit-tracker/internal/apperrors"
 	"github.com/uygardeniz/habit-tracker/internal/dto"
 	"github.com/uygardeniz/habit-tracker/internal/entity"
@@ -12,12 +13,18 @@ import (
 
 type UpdateHabitUsecase struct {
 	habitRepository repository.HabitRepository
+	userRepository  repository.UserRepository
 }
 
-func NewUpdateHabitUsecase(habitRepository repository.HabitRepository) *UpdateHabitUsecase {
-	return &UpdateHabitUsecase{habitRepository: habitRepository}
+func NewUpdateHabitUsecase(habitRepository repository.HabitRepository, userRepository repository.UserRepository) *UpdateHabitUsecase {
+	return &UpdateHabitUsecase{
+		habitRepository: habitRepository,
+		userRepository:  userRepository,
+	}
 }
 
+// Execute applies the changes in req to the habit. A schedule change takes effect from
+// the user's local today; earlier days keep being judged by the schedule they had.
 func (uc *UpdateHabitUsecase) Execute(ctx context.Context, habitID string, userID string, req dto.UpdateHabitDTO) (*entity.Habit, error) {
 	habit, err := uc.habitRepository.FindByID(ctx, habitID)
 	if err != nil {
@@ -28,6 +35,13 @@ func (uc *UpdateHabitUsecase) Execute(ctx context.Context, habitID string, userI
 		return nil, apperrors.ErrForbidden
 	}
 
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := user.Today(time.Now())
+	before := habit.Schedule
+
 	// Apply updates from DTO to entity
 	if req.Name != nil {
 		habit.Name = *req.Name
@@ -44,12 +58,62 @@ func (uc *UpdateHabitUsecase) Execute(ctx context.Context, habitID string, userI
 	if req.Category != nil {
 		habit.SetCategory(*req.Category)
 	}
+	if req.Kind != nil && *req.Kind != habit.Kind {
+		if habit.TotalCompletions > 0 {
+			return nil, entity.ErrKindChangeWithHistory
+		}
+		habit.Kind = *req.Kind
+		// Count habits have no unit or target value
+		if !habit.IsMeasurable() {
+			habit.Unit = nil
+			habit.TargetValue = nil
+		}
+	}
+	if req.Unit != nil {
+		habit.Unit = req.Unit
+	}
+	if req.TargetValue != nil {
+		habit.TargetValue = req.TargetValue
+	}
 	if req.Frequency != nil {
 		habit.Frequency = *req.Frequency
+		// Drop schedule settings that don't apply to the new frequency
+		if !entity.IsPeriodQuota(habit.Frequency) {
+			habit.PeriodTarget = nil
+		}
+		if habit.Frequency != "interval" {
+			habit.IntervalDays = nil
+		}
+		if habit.Frequency != "custom" {
+			habit.Recurrence = nil
+		}
+		if habit.Frequency != "interval" && habit.Frequency != "custom" {
+			habit.AnchorDate = nil
+		}
 	}
 	if req.TargetCount != nil {
 		habit.TargetCount = *req.TargetCount
 	}
+	if req.PeriodTarget != nil {
+		habit.PeriodTarget = req.PeriodTarget
+	}
+	if req.IntervalDays != nil {
+		habit.IntervalDays = req.IntervalDays
+	}
+	if req.AnchorDate != nil {
+		anchorDate, err := dto.ParseDate(req.AnchorDate)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+		habit.AnchorDate = anchorDate
+	}
+	if req.Recurrence != nil {
+		habit.Recurrence = req.Recurrence
+	}
+	if (habit.Frequency == "interval" || habit.Frequency == "custom") && habit.AnchorDate == nil {
+		anchorDate := today
+		habit.AnchorDate = &anchorDate
+	}
 
 	if req.TargetDays != nil {
 		targetDays, err := dto.ConvertTargetDaysFromJSON(req.TargetDays)
@@ -81,7 +145,12 @@ func (uc *UpdateHabitUsecase) Execute(ctx context.Context, habitID string, userI
 		return nil, apperrors.ErrInvalidInput
 	}
 
-	err = uc.habitRepository.Update(ctx, habit)
+	var revision *entity.ScheduleRevision
+	if habit.ScheduleChanged(before) {
+		revision = habit.ReviseSchedule(uuid.New().String(), today)
+	}
+
+	err = uc.habitRepository.Update(ctx, habit, revision, today)
 	if err != nil {
 		return nil, err
 	}
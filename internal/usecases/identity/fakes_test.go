@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+// fakeIdentityRepository stands in for the identity repository in this package's tests.
+// It only implements the methods the tests reach, so calling any other panics.
+type fakeIdentityRepository struct {
+	repository.IdentityRepository
+	identities []*entity.UserIdentity
+}
+
+func (f *fakeIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (f *fakeIdentityRepository) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	for _, existing := range f.identities {
+		if existing.Provider == identity.Provider && (existing.Subject == identity.Subject || existing.UserID == identity.UserID) {
+			return apperrors.ErrAlreadyExists
+		}
+	}
+	f.identities = append(f.identities, identity)
+	return nil
+}
@@ -0,0 +1,21 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type GetIdentitiesUsecase struct {
+	identityRepo repository.IdentityRepository
+}
+
+func NewGetIdentitiesUsecase(identityRepo repository.IdentityRepository) *GetIdentitiesUsecase {
+	return &GetIdentitiesUsecase{identityRepo: identityRepo}
+}
+
+// Execute returns the identity providers linked to the user, oldest first
+func (uc *GetIdentitiesUsecase) Execute(ctx context.Context, userID string) ([]*entity.UserIdentity, error) {
+	return uc.identityRepo.FindByUserID(ctx, userID)
+}
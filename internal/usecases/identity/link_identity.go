@@ -0,0 +1,46 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/oidc"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type LinkIdentityUsecase struct {
+	identityRepo repository.IdentityRepository
+}
+
+func NewLinkIdentityUsecase(identityRepo repository.IdentityRepository) *LinkIdentityUsecase {
+	return &LinkIdentityUsecase{identityRepo: identityRepo}
+}
+
+// Execute links the account the user logged in to at the provider to their own. An
+// account linked to another user, or a second one of the provider, returns
+// apperrors.ErrAlreadyExists.
+func (uc *LinkIdentityUsecase) Execute(ctx context.Context, userID, provider string, profile *oidc.Profile) (*entity.UserIdentity, error) {
+	existing, err := uc.identityRepo.FindByProviderSubject(ctx, provider, profile.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, apperrors.ErrAlreadyExists
+		}
+		return existing, nil
+	}
+	if err != apperrors.ErrNotFound {
+		return nil, err
+	}
+
+	identity, err := entity.NewUserIdentity(uuid.NewString(), userID, provider, profile.Subject, profile.Email)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	if err := uc.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,77 @@
+package identity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/oidc"
+)
+
+func TestLinkIdentityUsecase(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []*entity.UserIdentity
+		provider string
+		profile  oidc.Profile
+		wantID   string
+		wantErr  error
+	}{
+		{
+			name:     "new identity",
+			provider: "acme",
+			profile:  oidc.Profile{Subject: "sub-1", Email: "ada@example.com"},
+		},
+		{
+			name:     "identity already linked to the user",
+			existing: []*entity.UserIdentity{{ID: "identity-1", UserID: "user-1", Provider: "acme", Subject: "sub-1"}},
+			provider: "acme",
+			profile:  oidc.Profile{Subject: "sub-1"},
+			wantID:   "identity-1",
+		},
+		{
+			name:     "identity linked to another user",
+			existing: []*entity.UserIdentity{{ID: "identity-1", UserID: "user-2", Provider: "acme", Subject: "sub-1"}},
+			provider: "acme",
+			profile:  oidc.Profile{Subject: "sub-1"},
+			wantErr:  apperrors.ErrAlreadyExists,
+		},
+		{
+			name:     "second identity of the provider",
+			existing: []*entity.UserIdentity{{ID: "identity-1", UserID: "user-1", Provider: "acme", Subject: "sub-1"}},
+			provider: "acme",
+			profile:  oidc.Profile{Subject: "sub-2"},
+			wantErr:  apperrors.ErrAlreadyExists,
+		},
+		{
+			name:     "missing subject",
+			provider: "acme",
+			wantErr:  apperrors.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeIdentityRepository{identities: tt.existing}
+
+			identity, err := NewLinkIdentityUsecase(repo).Execute(context.Background(), "user-1", tt.provider, &tt.profile)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, identity)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "user-1", identity.UserID)
+			assert.Equal(t, tt.provider, identity.Provider)
+			assert.Equal(t, tt.profile.Subject, identity.Subject)
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, identity.ID)
+			}
+			assert.Contains(t, repo.identities, identity)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type UnlinkIdentityUsecase struct {
+	identityRepo repository.IdentityRepository
+}
+
+func NewUnlinkIdentityUsecase(identityRepo repository.IdentityRepository) *UnlinkIdentityUsecase {
+	return &UnlinkIdentityUsecase{identityRepo: identityRepo}
+}
+
+// Execute unlinks one of the user's identity providers. The last one can't be unlinked
+// unless the user has a password, and returns entity.ErrLastLoginMethod.
+func (uc *UnlinkIdentityUsecase) Execute(ctx context.Context, identityID, userID string) error {
+	identity, err := uc.identityRepo.FindByID(ctx, identityID)
+	if err != nil {
+		return err
+	}
+
+	if identity.UserID != userID {
+		return apperrors.ErrForbidden
+	}
+
+	return uc.identityRepo.Delete(ctx, identity)
+}
@@ -0,0 +1,22 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type GetSessionsUsecase struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewGetSessionsUsecase(sessionRepo repository.SessionRepository) *GetSessionsUsecase {
+	return &GetSessionsUsecase{sessionRepo: sessionRepo}
+}
+
+// Execute returns the user's active sessions, most recently used first
+func (uc *GetSessionsUsecase) Execute(ctx context.Context, userID string) ([]*entity.Session, error) {
+	return uc.sessionRepo.FindActiveByUserID(ctx, userID, time.Now())
+}
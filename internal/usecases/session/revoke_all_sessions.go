@@ -0,0 +1,21 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type RevokeAllSessionsUsecase struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewRevokeAllSessionsUsecase(sessionRepo repository.SessionRepository) *RevokeAllSessionsUsecase {
+	return &RevokeAllSessionsUsecase{sessionRepo: sessionRepo}
+}
+
+// Execute logs the user out everywhere, including the session making the request
+func (uc *RevokeAllSessionsUsecase) Execute(ctx context.Context, userID string) error {
+	return uc.sessionRepo.RevokeAllByUserID(ctx, userID, time.Now())
+}
@@ -0,0 +1,31 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type RevokeSessionUsecase struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewRevokeSessionUsecase(sessionRepo repository.SessionRepository) *RevokeSessionUsecase {
+	return &RevokeSessionUsecase{sessionRepo: sessionRepo}
+}
+
+// Execute logs the user out of one of their sessions
+func (uc *RevokeSessionUsecase) Execute(ctx context.Context, sessionID, userID string) error {
+	session, err := uc.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID != userID {
+		return apperrors.ErrForbidden
+	}
+
+	return uc.sessionRepo.Revoke(ctx, session.ID, time.Now())
+}
@@ -0,0 +1,35 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type ValidateSessionUsecase struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewValidateSessionUsecase(sessionRepo repository.SessionRepository) *ValidateSessionUsecase {
+	return &ValidateSessionUsecase{sessionRepo: sessionRepo}
+}
+
+// Execute checks that the session an access token was issued for is the user's and is
+// still active. Revoked, expired and unknown sessions return apperrors.ErrUnauthorized.
+func (uc *ValidateSessionUsecase) Execute(ctx context.Context, sessionID, userID string) error {
+	session, err := uc.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if err == apperrors.ErrNotFound {
+			return apperrors.ErrUnauthorized
+		}
+		return err
+	}
+
+	if session.UserID != userID || !session.IsActive(time.Now()) {
+		return apperrors.ErrUnauthorized
+	}
+
+	return nil
+}
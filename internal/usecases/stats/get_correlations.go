@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+const (
+	defaultCorrelationDays       = 90
+	maxCorrelationDays           = 366
+	defaultCorrelationMinSamples = 14
+)
+
+type GetCorrelationsUsecase struct {
+	habitRepository      repository.HabitRepository
+	completionRepository repository.CompletionRepository
+	timeOffRepository    repository.TimeOffRepository
+	userRepository       repository.UserRepository
+}
+
+func NewGetCorrelationsUsecase(habitRepository repository.HabitRepository, completionRepository repository.CompletionRepository, timeOffRepository repository.TimeOffRepository, userRepository repository.UserRepository) *GetCorrelationsUsecase {
+	return &GetCorrelationsUsecase{
+		habitRepository:      habitRepository,
+		completionRepository: completionRepository,
+		timeOffRepository:    timeOffRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// Execute correlates the user's habits pairwise. The range defaults to the 90 days ending
+// yesterday, since today is still in progress, and never reaches past the user's today.
+func (uc *GetCorrelationsUsecase) Execute(ctx context.Context, userID string, query dto.CorrelationsQueryDTO) (*entity.CorrelationReport, error) {
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := user.Today(time.Now())
+
+	to := today.AddDate(0, 0, -1)
+	if query.To != nil && *query.To != "" {
+		to, err = time.Parse("2006-01-02", *query.To)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+		if to.After(today) {
+			to = today
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultCorrelationDays - 1))
+	if query.From != nil && *query.From != "" {
+		from, err = time.Parse("2006-01-02", *query.From)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+
+	if from.After(to) || to.Sub(from).Hours()/24 >= maxCorrelationDays {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	minSamples := defaultCorrelationMinSamples
+	if query.MinSamples != nil {
+		minSamples = *query.MinSamples
+	}
+
+	habits, err := uc.habitRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	completions, err := uc.completionRepository.FindAllByUserIDInRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	timeOff, err := uc.timeOffRepository.FindByUserID(ctx, userID, nil, &from, &to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.CorrelationReport{
+		From:         from,
+		To:           to,
+		MinSamples:   minSamples,
+		Correlations: entity.CalculateCorrelations(habits, completions, timeOff, from, to, minSamples),
+	}, nil
+}
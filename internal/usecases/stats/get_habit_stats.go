@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+const seriesBuckets = 12
+
+type GetHabitStatsUsecase struct {
+	habitRepository      repository.HabitRepository
+	completionRepository repository.CompletionRepository
+	timeOffRepository    repository.TimeOffRepository
+	userRepository       repository.UserRepository
+}
+
+func NewGetHabitStatsUsecase(habitRepository repository.HabitRepository, completionRepository repository.CompletionRepository, timeOffRepository repository.TimeOffRepository, userRepository repository.UserRepository) *GetHabitStatsUsecase {
+	return &GetHabitStatsUsecase{
+		habitRepository:      habitRepository,
+		completionRepository: completionRepository,
+		timeOffRepository:    timeOffRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// Execute computes the habit's statistics as of the user's local today. The time series
+// is weekly unless query.Series asks for monthly buckets.
+func (uc *GetHabitStatsUsecase) Execute(ctx context.Context, habitID, userID string, query dto.HabitStatsQueryDTO) (*entity.Habit, *entity.HabitStats, error) {
+	habit, err := uc.habitRepository.FindByID(ctx, habitID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if habit.UserID != userID {
+		return nil, nil, apperrors.ErrForbidden
+	}
+
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	completions, err := uc.completionRepository.FindAllByHabitID(ctx, habit.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timeOff, err := uc.timeOffRepository.FindByUserID(ctx, userID, &habit.ID, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	monthly := query.Series != nil && *query.Series == "monthly"
+	stats := habit.CalculateStats(completions, timeOff, user.Today(time.Now()), monthly, seriesBuckets)
+
+	return habit, stats, nil
+}
@@ -0,0 +1,138 @@
+package stats
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+const (
+	defaultHeatmapDays = 365
+	maxHeatmapDays     = 731
+)
+
+type GetHeatmapUsecase struct {
+	completionRepository repository.CompletionRepository
+	habitRepository      repository.HabitRepository
+	timeOffRepository    repository.TimeOffRepository
+	userRepository       repository.UserRepository
+}
+
+func NewGetHeatmapUsecase(completionRepository repository.CompletionRepository, habitRepository repository.HabitRepository, timeOffRepository repository.TimeOffRepository, userRepository repository.UserRepository) *GetHeatmapUsecase {
+	return &GetHeatmapUsecase{
+		completionRepository: completionRepository,
+		habitRepository:      habitRepository,
+		timeOffRepository:    timeOffRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// Execute aggregates the user's completions per day. The range defaults to the year
+// ending on the user's local today; query.HabitID narrows it to a single habit.
+func (uc *GetHeatmapUsecase) Execute(ctx context.Context, userID string, query dto.HeatmapQueryDTO) (*entity.Heatmap, error) {
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := user.Today(time.Now())
+	if query.To != nil && *query.To != "" {
+		to, err = time.Parse("2006-01-02", *query.To)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultHeatmapDays - 1))
+	if query.From != nil && *query.From != "" {
+		from, err = time.Parse("2006-01-02", *query.From)
+		if err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+
+	if from.After(to) || to.Sub(from).Hours()/24 >= maxHeatmapDays {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	var habits []*entity.Habit
+	if query.HabitID != nil {
+		habit, err := uc.habitRepository.FindByID(ctx, *query.HabitID)
+		if err != nil {
+			return nil, err
+		}
+		if habit.UserID != userID {
+			return nil, apperrors.ErrForbidden
+		}
+		habits = []*entity.Habit{habit}
+	} else {
+		habits, err = uc.habitRepository.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	aggregated, err := uc.completionRepository.AggregateDailyByUserID(ctx, userID, query.HabitID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	timeOff, err := uc.timeOffRepository.FindByUserID(ctx, userID, query.HabitID, &from, &to)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[time.Time]*entity.HeatmapDay, len(aggregated))
+	for _, day := range aggregated {
+		byDate[day.Date] = day
+	}
+
+	loc := user.Location()
+	heatmap := &entity.Heatmap{From: from, To: to}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day, ok := byDate[date]
+		if !ok {
+			day = &entity.HeatmapDay{Date: date}
+		}
+
+		// Only habits expected on the day count as fulfilled, so an entry on a day off
+		// neither adds to it nor, for quit habits, takes from it
+		for _, habit := range habits {
+			if !isExpectedOn(habit, date, loc) || timeOff.Covers(habit.ID, date) {
+				continue
+			}
+			day.Scheduled++
+			if habit.IsQuit() {
+				if !slices.Contains(day.SlipHabitIDs, habit.ID) {
+					day.Fulfilled++
+				}
+			} else if slices.Contains(day.FulfilledHabitIDs, habit.ID) {
+				day.Fulfilled++
+			}
+		}
+		day.Slips = len(day.SlipHabitIDs)
+
+		if !day.IsEmpty() {
+			heatmap.Days = append(heatmap.Days, day)
+		}
+	}
+
+	return heatmap, nil
+}
+
+// isExpectedOn reports whether an active habit was scheduled on date and already existed
+// on that day in the user's timezone
+func isExpectedOn(habit *entity.Habit, date time.Time, loc *time.Location) bool {
+	if !habit.IsActive {
+		return false
+	}
+	if date.Before(entity.DateOnly(habit.CreatedAt.In(loc))) {
+		return false
+	}
+	return habit.IsScheduledOn(date)
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type GetOverviewUsecase struct {
+	habitRepository      repository.HabitRepository
+	completionRepository repository.CompletionRepository
+	timeOffRepository    repository.TimeOffRepository
+	userRepository       repository.UserRepository
+}
+
+func NewGetOverviewUsecase(habitRepository repository.HabitRepository, completionRepository repository.CompletionRepository, timeOffRepository repository.TimeOffRepository, userRepository repository.UserRepository) *GetOverviewUsecase {
+	return &GetOverviewUsecase{
+		habitRepository:      habitRepository,
+		completionRepository: completionRepository,
+		timeOffRepository:    timeOffRepository,
+		userRepository:       userRepository,
+	}
+}
+
+// Execute summarizes all of the user's habits as of the user's local today
+func (uc *GetOverviewUsecase) Execute(ctx context.Context, userID string) (*entity.Overview, error) {
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := user.Today(time.Now())
+
+	habits, err := uc.habitRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The week can start in the previous month, so load whichever period starts first
+	from := entity.Schedule{Frequency: "times_per_week"}.PeriodStart(today)
+	if monthStart := (entity.Schedule{Frequency: "times_per_month"}).PeriodStart(today); monthStart.Before(from) {
+		from = monthStart
+	}
+
+	completions, err := uc.completionRepository.FindAllByUserIDInRange(ctx, userID, from, today)
+	if err != nil {
+		return nil, err
+	}
+
+	timeOff, err := uc.timeOffRepository.FindByUserID(ctx, userID, nil, &from, &today)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.BuildOverview(habits, completions, timeOff, today), nil
+}
@@ -0,0 +1,50 @@
+package timeoff
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type CreatePauseUsecase struct {
+	timeOffRepo repository.TimeOffRepository
+	userRepo    repository.UserRepository
+}
+
+func NewCreatePauseUsecase(timeOffRepo repository.TimeOffRepository, userRepo repository.UserRepository) *CreatePauseUsecase {
+	return &CreatePauseUsecase{
+		timeOffRepo: timeOffRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// Execute pauses all of the user's habits over the requested date range. Overlapping an
+// existing pause fails with ErrAlreadyExists.
+func (uc *CreatePauseUsecase) Execute(ctx context.Context, userID string, req dto.CreatePauseDTO) (*entity.Pause, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	pause, err := entity.NewPause(uuid.New().String(), userID, startDate, endDate, req.Reason)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	return uc.timeOffRepo.CreatePause(ctx, pause, user.Today(time.Now()))
+}
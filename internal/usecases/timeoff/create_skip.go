@@ -0,0 +1,55 @@
+package timeoff
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type CreateSkipUsecase struct {
+	timeOffRepo repository.TimeOffRepository
+	habitRepo   repository.HabitRepository
+	userRepo    repository.UserRepository
+}
+
+func NewCreateSkipUsecase(timeOffRepo repository.TimeOffRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *CreateSkipUsecase {
+	return &CreateSkipUsecase{
+		timeOffRepo: timeOffRepo,
+		habitRepo:   habitRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// Execute skips the habit on the requested day, which can be in the past or the future
+func (uc *CreateSkipUsecase) Execute(ctx context.Context, habitID, userID string, req dto.CreateSkipDTO) (*entity.HabitSkip, error) {
+	habit, err := uc.habitRepo.FindByID(ctx, habitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if habit.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	skip, err := entity.NewHabitSkip(uuid.New().String(), habit.ID, userID, date, req.Reason)
+	if err != nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	return uc.timeOffRepo.CreateSkip(ctx, skip, habit, user.Today(time.Now()))
+}
@@ -0,0 +1,39 @@
+package timeoff
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type DeletePauseUsecase struct {
+	timeOffRepo repository.TimeOffRepository
+	userRepo    repository.UserRepository
+}
+
+func NewDeletePauseUsecase(timeOffRepo repository.TimeOffRepository, userRepo repository.UserRepository) *DeletePauseUsecase {
+	return &DeletePauseUsecase{
+		timeOffRepo: timeOffRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (uc *DeletePauseUsecase) Execute(ctx context.Context, pauseID, userID string) error {
+	pause, err := uc.timeOffRepo.FindPauseByID(ctx, pauseID)
+	if err != nil {
+		return err
+	}
+
+	if pause.UserID != userID {
+		return apperrors.ErrForbidden
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return uc.timeOffRepo.DeletePause(ctx, pause, user.Today(time.Now()))
+}
@@ -0,0 +1,46 @@
+package timeoff
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type DeleteSkipUsecase struct {
+	timeOffRepo repository.TimeOffRepository
+	habitRepo   repository.HabitRepository
+	userRepo    repository.UserRepository
+}
+
+func NewDeleteSkipUsecase(timeOffRepo repository.TimeOffRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *DeleteSkipUsecase {
+	return &DeleteSkipUsecase{
+		timeOffRepo: timeOffRepo,
+		habitRepo:   habitRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (uc *DeleteSkipUsecase) Execute(ctx context.Context, skipID, userID string) error {
+	skip, err := uc.timeOffRepo.FindSkipByID(ctx, skipID)
+	if err != nil {
+		return err
+	}
+
+	if skip.UserID != userID {
+		return apperrors.ErrForbidden
+	}
+
+	habit, err := uc.habitRepo.FindByID(ctx, skip.HabitID)
+	if err != nil {
+		return err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return uc.timeOffRepo.DeleteSkip(ctx, skip.ID, habit, user.Today(time.Now()))
+}
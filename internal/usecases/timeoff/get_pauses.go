@@ -0,0 +1,22 @@
+package timeoff
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type GetPausesUsecase struct {
+	timeOffRepo repository.TimeOffRepository
+}
+
+func NewGetPausesUsecase(timeOffRepo repository.TimeOffRepository) *GetPausesUsecase {
+	return &GetPausesUsecase{
+		timeOffRepo: timeOffRepo,
+	}
+}
+
+func (uc *GetPausesUsecase) Execute(ctx context.Context, userID string) ([]*entity.Pause, error) {
+	return uc.timeOffRepo.FindPausesByUserID(ctx, userID)
+}
@@ -0,0 +1,30 @@
+package timer
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type GetTimerUsecase struct {
+	timerRepo repository.TimerRepository
+	habitRepo repository.HabitRepository
+}
+
+func NewGetTimerUsecase(timerRepo repository.TimerRepository, habitRepo repository.HabitRepository) *GetTimerUsecase {
+	return &GetTimerUsecase{
+		timerRepo: timerRepo,
+		habitRepo: habitRepo,
+	}
+}
+
+// Execute returns the habit's running or paused session, or ErrNotFound if there is none
+func (uc *GetTimerUsecase) Execute(ctx context.Context, habitID, userID string) (*entity.TimerSession, error) {
+	habit, err := findTimerHabit(ctx, uc.habitRepo, habitID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.timerRepo.FindActiveByHabitID(ctx, habit.ID)
+}
@@ -0,0 +1,46 @@
+package timer
+
+import (
+	"context"
+	"time"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type PauseTimerUsecase struct {
+	timerRepo repository.TimerRepository
+	habitRepo repository.HabitRepository
+}
+
+func NewPauseTimerUsecase(timerRepo repository.TimerRepository, habitRepo repository.HabitRepository) *PauseTimerUsecase {
+	return &PauseTimerUsecase{
+		timerRepo: timerRepo,
+		habitRepo: habitRepo,
+	}
+}
+
+// Execute pauses the habit's running session. A habit without a running session fails
+// with ErrNotFound.
+func (uc *PauseTimerUsecase) Execute(ctx context.Context, habitID, userID string) (*entity.TimerSession, error) {
+	habit, err := findTimerHabit(ctx, uc.habitRepo, habitID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := uc.timerRepo.FindActiveByHabitID(ctx, habit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := session.Pause(time.Now()); err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	if err := uc.timerRepo.Update(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
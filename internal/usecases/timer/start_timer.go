@@ -0,0 +1,58 @@
+package timer
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type StartTimerUsecase struct {
+	timerRepo repository.TimerRepository
+	habitRepo repository.HabitRepository
+}
+
+func NewStartTimerUsecase(timerRepo repository.TimerRepository, habitRepo repository.HabitRepository) *StartTimerUsecase {
+	return &StartTimerUsecase{
+		timerRepo: timerRepo,
+		habitRepo: habitRepo,
+	}
+}
+
+// Execute starts a new session for the habit, or resumes its paused one. Starting a
+// habit whose timer is already running fails with ErrAlreadyExists.
+func (uc *StartTimerUsecase) Execute(ctx context.Context, habitID, userID string) (*entity.TimerSession, error) {
+	habit, err := findTimerHabit(ctx, uc.habitRepo, habitID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !habit.IsActive {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	now := time.Now()
+
+	session, err := uc.timerRepo.FindActiveByHabitID(ctx, habit.ID)
+	if err != nil && err != apperrors.ErrNotFound {
+		return nil, err
+	}
+
+	if session != nil {
+		if session.IsRunning() {
+			return nil, apperrors.ErrAlreadyExists
+		}
+		if err := session.Resume(now); err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+		if err := uc.timerRepo.Update(ctx, session); err != nil {
+			return nil, err
+		}
+		return session, nil
+	}
+
+	return uc.timerRepo.Create(ctx, entity.NewTimerSession(uuid.New().String(), habit.ID, userID, now))
+}
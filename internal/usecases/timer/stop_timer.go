@@ -0,0 +1,66 @@
+package timer
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type StopTimerUsecase struct {
+	timerRepo repository.TimerRepository
+	habitRepo repository.HabitRepository
+	userRepo  repository.UserRepository
+}
+
+func NewStopTimerUsecase(timerRepo repository.TimerRepository, habitRepo repository.HabitRepository, userRepo repository.UserRepository) *StopTimerUsecase {
+	return &StopTimerUsecase{
+		timerRepo: timerRepo,
+		habitRepo: habitRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Execute stops the habit's running or paused session and adds its whole minutes to the
+// completion of the day the session started, in the user's timezone. Sessions shorter
+// than a minute are stopped without a completion, so the returned completion is nil.
+func (uc *StopTimerUsecase) Execute(ctx context.Context, habitID, userID string) (*entity.TimerSession, *entity.HabitCompletion, error) {
+	habit, err := findTimerHabit(ctx, uc.habitRepo, habitID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := uc.timerRepo.FindActiveByHabitID(ctx, habit.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if err := session.Stop(now); err != nil {
+		return nil, nil, apperrors.ErrNotFound
+	}
+
+	var completion *entity.HabitCompletion
+	if minutes := session.Minutes(); minutes > 0 {
+		date := entity.DateOnly(session.StartedAt.In(user.Location()))
+		completion, err = entity.NewHabitCompletion(uuid.New().String(), habit.ID, userID, date, minutes, nil, habit.TargetCount, nil, nil)
+		if err != nil {
+			return nil, nil, apperrors.ErrInvalidInput
+		}
+	}
+
+	completion, err = uc.timerRepo.Stop(ctx, session, completion, habit, user.Today(now))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, completion, nil
+}
@@ -0,0 +1,28 @@
+package timer
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+// findTimerHabit loads a habit owned by userID that can be timed. Only duration habits
+// count minutes, so other kinds are rejected.
+func findTimerHabit(ctx context.Context, habitRepo repository.HabitRepository, habitID, userID string) (*entity.Habit, error) {
+	habit, err := habitRepo.FindByID(ctx, habitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if habit.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+
+	if !habit.IsDuration() {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	return habit, nil
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"context"
+
+	"github.com/uygardeniz/habit-tracker/internal/apperrors"
+	"github.com/uygardeniz/habit-tracker/internal/dto"
+	"github.com/uygardeniz/habit-tracker/internal/entity"
+	"github.com/uygardeniz/habit-tracker/internal/middleware"
+	"github.com/uygardeniz/habit-tracker/internal/repository"
+)
+
+type UpdateMeUsecase struct {
+	userRepository repository.UserRepository
+}
+
+func NewUpdateMeUsecase(userRepository repository.UserRepository) *UpdateMeUsecase {
+	return &UpdateMeUsecase{userRepository: userRepository}
+}
+
+func (uc *UpdateMeUsecase) Execute(ctx context.Context, req dto.UpdateMeDTO) (*entity.User, error) {
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, apperrors.ErrNotFound
+	}
+
+	user, err := uc.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Timezone != nil {
+		if err := user.SetTimezone(*req.Timezone); err != nil {
+			return nil, apperrors.ErrInvalidInput
+		}
+	}
+
+	if err := uc.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
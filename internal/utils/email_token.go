@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateEmailToken creates a random token to send by email, along with the hash to
+// store in its place
+func GenerateEmailToken() (token, tokenHash string, err error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(tokenBytes)
+	return token, HashEmailToken(token), nil
+}
+
+// HashEmailToken returns the hash a token from GenerateEmailToken is stored under
+func HashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -8,8 +8,9 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateAccessToken generates a JWT access token for the given user ID
-func GenerateAccessToken(userID string) (string, error) {
+// GenerateAccessToken generates a JWT access token for the given user ID, valid while
+// the session it was issued for is
+func GenerateAccessToken(userID, sessionID string) (string, error) {
 	secretKey := os.Getenv("JWT_ACCESS_SECRET")
 	if secretKey == "" {
 		return "", errors.New("JWT_ACCESS_SECRET environment variable is not set")
@@ -20,6 +21,7 @@ func GenerateAccessToken(userID string) (string, error) {
 
 	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub": userID,
+		"sid": sessionID,
 		"exp": time.Now().Add(time.Minute * 15).Unix(),
 		"iat": time.Now().Unix(),
 	})
@@ -32,13 +34,15 @@ func GenerateAccessToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
-// GenerateRefreshToken generates a JWT refresh token for the given user ID
-func GenerateRefreshToken(userID string) (string, error) {
+// GenerateRefreshToken signs the refresh token with the given ID for the user. The token
+// is only honored while its server side record is.
+func GenerateRefreshToken(userID, tokenID string, expiresAt time.Time) (string, error) {
 	secretKey := os.Getenv("JWT_REFRESH_SECRET")
 
 	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub": userID,
-		"exp": time.Now().Add(time.Hour * 24 * 7).Unix(),
+		"jti": tokenID,
+		"exp": expiresAt.Unix(),
 		"iat": time.Now().Unix(),
 	})
 
@@ -50,6 +54,30 @@ func GenerateRefreshToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
+// ParseRefreshToken validates a refresh token and returns its user and token IDs
+func ParseRefreshToken(tokenString string) (userID, tokenID string, err error) {
+	token, err := ValidateToken(tokenString, os.Getenv("JWT_REFRESH_SECRET"))
+	if err != nil {
+		return "", "", err
+	}
+	if !token.Valid {
+		return "", "", errors.New("invalid refresh token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", errors.New("failed to parse token claims")
+	}
+
+	userID, _ = claims["sub"].(string)
+	tokenID, _ = claims["jti"].(string)
+	if userID == "" || tokenID == "" {
+		return "", "", errors.New("invalid refresh token claims")
+	}
+
+	return userID, tokenID, nil
+}
+
 // ValidateToken validates a JWT token with the given secret
 func ValidateToken(tokenString, secret string) (*jwt.Token, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
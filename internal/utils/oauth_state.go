@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// OAuthStateTTL is how long a login started with the identity provider stays valid
+const OAuthStateTTL = 10 * time.Minute
+
+var ErrOAuthStateMismatch = errors.New("oauth state does not match")
+
+// OAuthState holds the values a single OAuth login is bound to. Token carries them
+// signed, to be kept by the browser until the callback.
+type OAuthState struct {
+	State    string
+	Verifier string
+	// Sent to the provider and checked against the ID token it returns
+	Nonce string
+	// The identity provider the login was started with
+	Provider string
+	// The user linking the provider to their account, empty when logging in
+	LinkUserID string
+	Token      string
+	ExpiresAt  time.Time
+}
+
+func oauthStateSecret() ([]byte, error) {
+	secretKey := os.Getenv("OAUTH_STATE_SECRET")
+	if secretKey == "" {
+		return nil, errors.New("OAUTH_STATE_SECRET environment variable is not set")
+	}
+	if len(secretKey) < 32 {
+		return nil, errors.New("OAUTH_STATE_SECRET must be at least 32 characters")
+	}
+	return []byte(secretKey), nil
+}
+
+// GenerateOAuthState creates a random state and PKCE verifier for a new login with the
+// provider, or for linking it to the account of linkUserID when not empty
+func GenerateOAuthState(provider, linkUserID string) (*OAuthState, error) {
+	secretKey, err := oauthStateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	stateBytes := make([]byte, 32)
+	if _, err := rand.Read(stateBytes); err != nil {
+		return nil, err
+	}
+
+	nonceBytes := make([]byte, 32)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	oauthState := &OAuthState{
+		State:      base64.RawURLEncoding.EncodeToString(stateBytes),
+		Verifier:   oauth2.GenerateVerifier(),
+		Nonce:      base64.RawURLEncoding.EncodeToString(nonceBytes),
+		Provider:   provider,
+		LinkUserID: linkUserID,
+		ExpiresAt:  now.Add(OAuthStateTTL),
+	}
+
+	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"state":    oauthState.State,
+		"verifier": oauthState.Verifier,
+		"nonce":    oauthState.Nonce,
+		"provider": oauthState.Provider,
+		"link":     oauthState.LinkUserID,
+		"exp":      oauthState.ExpiresAt.Unix(),
+		"iat":      now.Unix(),
+	})
+
+	oauthState.Token, err = claims.SignedString(secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauthState, nil
+}
+
+// ValidateOAuthState checks that token was issued by GenerateOAuthState for the provider,
+// hasn't expired and belongs to the state the provider returned
+func ValidateOAuthState(tokenString, provider, state string) (*OAuthState, error) {
+	secretKey, err := oauthStateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := ValidateToken(tokenString, string(secretKey))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid oauth state token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("failed to parse oauth state claims")
+	}
+
+	tokenState, _ := claims["state"].(string)
+	verifier, _ := claims["verifier"].(string)
+	nonce, _ := claims["nonce"].(string)
+	tokenProvider, _ := claims["provider"].(string)
+	linkUserID, _ := claims["link"].(string)
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil || tokenState == "" || verifier == "" || nonce == "" {
+		return nil, errors.New("invalid oauth state claims")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tokenState), []byte(state)) != 1 || tokenProvider != provider {
+		return nil, ErrOAuthStateMismatch
+	}
+
+	return &OAuthState{
+		State:      tokenState,
+		Verifier:   verifier,
+		Nonce:      nonce,
+		Provider:   tokenProvider,
+		LinkUserID: linkUserID,
+		Token:      tokenString,
+		ExpiresAt:  expiresAt.Time,
+	}, nil
+}
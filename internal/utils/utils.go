@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -62,6 +64,8 @@ func WriteValidationErrorResponse(w http.ResponseWriter, _ int, initialData APIR
 				formattedErrors.Errors[i] = fmt.Sprintf("%s must be one of the following: %s", field, param)
 			case "hexcolor":
 				formattedErrors.Errors[i] = fmt.Sprintf("%s must be a valid hex color code", field)
+			case "timezone":
+				formattedErrors.Errors[i] = fmt.Sprintf("%s must be a valid IANA timezone name", field)
 			case "json":
 				formattedErrors.Errors[i] = fmt.Sprintf("%s must be a valid JSON array", field)
 			default:
@@ -78,3 +82,33 @@ func WriteValidationErrorResponse(w http.ResponseWriter, _ int, initialData APIR
 		WriteJSON(w, http.StatusInternalServerError, APIResponse{"error": "an unexpected error occurred during input validation"}, logger)
 	}
 }
+
+// ClientIP returns the IP address the request came from
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// IsSecureURL reports whether rawURL is an https URL, or an http one to the local host,
+// which never leaves the machine
+func IsSecureURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		if parsed.Hostname() == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(parsed.Hostname())
+		return ip != nil && ip.IsLoopback()
+	default:
+		return false
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 	"github.com/uygardeniz/habit-tracker/internal/app"
@@ -24,6 +25,7 @@ func main() {
 	}
 
 	defer application.DB.Close()
+	defer application.Scheduler.Stop()
 
 	router := routes.SetupRoutes(application)
 
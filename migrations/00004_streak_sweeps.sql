@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE streak_sweep_runs (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sweep_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (user_id, sweep_date),
+    CONSTRAINT streak_sweep_runs_status_check CHECK (status IN ('running', 'completed'))
+);
+
+CREATE TABLE streak_sweep_resets (
+    user_id UUID NOT NULL,
+    sweep_date DATE NOT NULL,
+    habit_id UUID NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+    previous_streak INTEGER NOT NULL,
+    reset_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, sweep_date, habit_id),
+    FOREIGN KEY (user_id, sweep_date) REFERENCES streak_sweep_runs(user_id, sweep_date) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS streak_sweep_resets;
+DROP TABLE IF EXISTS streak_sweep_runs;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE habit_completions ADD COLUMN target_count INTEGER NOT NULL DEFAULT 1;
+
+UPDATE habit_completions c
+SET target_count = h.target_count
+FROM habits h
+WHERE h.id = c.habit_id;
+
+ALTER TABLE habit_completions
+    ADD CONSTRAINT habit_completions_target_count_positive CHECK (target_count > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habit_completions DROP COLUMN IF EXISTS target_count;
+-- +goose StatementEnd
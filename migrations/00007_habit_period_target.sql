@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE habits ADD COLUMN period_target INTEGER;
+
+ALTER TABLE habits DROP CONSTRAINT habits_frequency_check;
+ALTER TABLE habits ADD CONSTRAINT habits_frequency_check
+    CHECK (frequency IN ('daily', 'weekly', 'monthly', 'times_per_week', 'times_per_month', 'custom'));
+
+ALTER TABLE habits ADD CONSTRAINT habits_period_target_positive CHECK (period_target IS NULL OR period_target > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_period_target_positive;
+ALTER TABLE habits DROP CONSTRAINT habits_frequency_check;
+ALTER TABLE habits ADD CONSTRAINT habits_frequency_check
+    CHECK (frequency IN ('daily', 'weekly', 'monthly', 'custom'));
+ALTER TABLE habits DROP COLUMN IF EXISTS period_target;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE habits ADD COLUMN interval_days INTEGER;
+ALTER TABLE habits ADD COLUMN anchor_date DATE;
+
+ALTER TABLE habits DROP CONSTRAINT habits_frequency_check;
+ALTER TABLE habits ADD CONSTRAINT habits_frequency_check
+    CHECK (frequency IN ('daily', 'weekly', 'monthly', 'times_per_week', 'times_per_month', 'interval', 'custom'));
+
+ALTER TABLE habits ADD CONSTRAINT habits_interval_days_positive CHECK (interval_days IS NULL OR interval_days > 0);
+ALTER TABLE habits ADD CONSTRAINT habits_interval_requires_anchor
+    CHECK (frequency <> 'interval' OR (interval_days IS NOT NULL AND anchor_date IS NOT NULL));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_interval_requires_anchor;
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_interval_days_positive;
+ALTER TABLE habits DROP CONSTRAINT habits_frequency_check;
+ALTER TABLE habits ADD CONSTRAINT habits_frequency_check
+    CHECK (frequency IN ('daily', 'weekly', 'monthly', 'times_per_week', 'times_per_month', 'custom'));
+ALTER TABLE habits DROP COLUMN IF EXISTS anchor_date;
+ALTER TABLE habits DROP COLUMN IF EXISTS interval_days;
+-- +goose StatementEnd
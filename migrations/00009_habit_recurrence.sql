@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE habits ADD COLUMN recurrence TEXT;
+
+ALTER TABLE habits ADD CONSTRAINT habits_custom_requires_recurrence
+    CHECK (frequency <> 'custom' OR (recurrence IS NOT NULL AND anchor_date IS NOT NULL));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_custom_requires_recurrence;
+ALTER TABLE habits DROP COLUMN IF EXISTS recurrence;
+-- +goose StatementEnd
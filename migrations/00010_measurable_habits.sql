@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE habits
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'count',
+    ADD COLUMN unit VARCHAR(50),
+    ADD COLUMN target_value NUMERIC(12, 4);
+
+ALTER TABLE habits ADD CONSTRAINT habits_kind_check CHECK (kind IN ('count', 'measurable'));
+
+ALTER TABLE habits ADD CONSTRAINT habits_measurable_requires_target
+    CHECK (kind <> 'measurable' OR (unit IS NOT NULL AND target_value IS NOT NULL AND target_value > 0));
+
+ALTER TABLE habit_completions
+    ADD COLUMN value NUMERIC(12, 4),
+    ADD COLUMN target_value NUMERIC(12, 4);
+
+ALTER TABLE habit_completions
+    ADD CONSTRAINT habit_completions_value_non_negative CHECK (value IS NULL OR value >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habit_completions DROP CONSTRAINT IF EXISTS habit_completions_value_non_negative;
+ALTER TABLE habit_completions DROP COLUMN IF EXISTS target_value;
+ALTER TABLE habit_completions DROP COLUMN IF EXISTS value;
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_measurable_requires_target;
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_kind_check;
+ALTER TABLE habits DROP COLUMN IF EXISTS target_value;
+ALTER TABLE habits DROP COLUMN IF EXISTS unit;
+ALTER TABLE habits DROP COLUMN IF EXISTS kind;
+-- +goose StatementEnd
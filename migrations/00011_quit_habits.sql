@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_kind_check;
+ALTER TABLE habits ADD CONSTRAINT habits_kind_check CHECK (kind IN ('count', 'measurable', 'quit'));
+
+ALTER TABLE habits ADD CONSTRAINT habits_quit_requires_daily
+    CHECK (kind <> 'quit' OR frequency = 'daily');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE habits SET kind = 'count' WHERE kind = 'quit';
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_quit_requires_daily;
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_kind_check;
+ALTER TABLE habits ADD CONSTRAINT habits_kind_check CHECK (kind IN ('count', 'measurable'));
+-- +goose StatementEnd
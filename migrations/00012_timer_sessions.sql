@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_kind_check;
+ALTER TABLE habits ADD CONSTRAINT habits_kind_check CHECK (kind IN ('count', 'measurable', 'quit', 'duration'));
+
+CREATE TABLE timer_sessions (
+    id UUID PRIMARY KEY,
+    habit_id UUID NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resumed_at TIMESTAMP WITH TIME ZONE,
+    elapsed_seconds INTEGER NOT NULL DEFAULT 0,
+    stopped_at TIMESTAMP WITH TIME ZONE,
+    completion_id UUID REFERENCES habit_completions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT timer_sessions_status_check CHECK (status IN ('running', 'paused', 'stopped')),
+    CONSTRAINT timer_sessions_elapsed_non_negative CHECK (elapsed_seconds >= 0),
+    CONSTRAINT timer_sessions_running_has_resumed_at CHECK (status <> 'running' OR resumed_at IS NOT NULL)
+);
+
+-- A habit has at most one running or paused timer
+CREATE UNIQUE INDEX timer_sessions_one_open_per_habit ON timer_sessions (habit_id) WHERE status <> 'stopped';
+CREATE INDEX timer_sessions_user_id_idx ON timer_sessions (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS timer_sessions;
+UPDATE habits SET kind = 'count' WHERE kind = 'duration';
+ALTER TABLE habits DROP CONSTRAINT IF EXISTS habits_kind_check;
+ALTER TABLE habits ADD CONSTRAINT habits_kind_check CHECK (kind IN ('count', 'measurable', 'quit'));
+-- +goose StatementEnd
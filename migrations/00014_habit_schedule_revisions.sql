@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE habit_schedule_revisions (
+    id UUID PRIMARY KEY,
+    habit_id UUID NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+    effective_from DATE NOT NULL,
+    frequency VARCHAR(20) NOT NULL,
+    target_count INTEGER NOT NULL,
+    target_days JSONB,
+    period_target INTEGER,
+    interval_days INTEGER,
+    anchor_date DATE,
+    recurrence TEXT,
+    target_value NUMERIC(12, 4),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(habit_id, effective_from)
+);
+
+-- Every existing habit has followed its current schedule since it was created
+INSERT INTO habit_schedule_revisions (id, habit_id, effective_from, frequency, target_count, target_days, period_target, interval_days, anchor_date, recurrence, target_value, created_at)
+SELECT gen_random_uuid(), h.id, (h.created_at AT TIME ZONE COALESCE(NULLIF(u.timezone, ''), 'UTC'))::date,
+       h.frequency, h.target_count, h.target_days, h.period_target, h.interval_days, h.anchor_date, h.recurrence, h.target_value, NOW()
+FROM habits h
+JOIN users u ON u.id = h.user_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS habit_schedule_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    -- The user's ID at the provider, the OIDC sub claim
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(provider, subject),
+    UNIQUE(user_id, provider)
+);
+
+INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+SELECT gen_random_uuid(), id, 'google', google_id, email, created_at
+FROM users
+WHERE google_id IS NOT NULL;
+
+ALTER TABLE users DROP COLUMN google_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN google_id VARCHAR(255) UNIQUE;
+
+UPDATE users u
+SET google_id = i.subject
+FROM user_identities i
+WHERE i.user_id = u.id AND i.provider = 'google';
+
+DROP TABLE IF EXISTS user_identities;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- States of completed OAuth callbacks, kept until they expire so a callback can't be
+-- replayed on any instance
+CREATE TABLE used_oauth_states (
+    state VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX used_oauth_states_expires_at_idx ON used_oauth_states (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS used_oauth_states;
+-- +goose StatementEnd
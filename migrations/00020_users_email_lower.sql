@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Emails are stored lowercased and are unique regardless of case. Addresses that only
+-- differ in case make this fail and have to be merged by hand first.
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+
+CREATE UNIQUE INDEX users_email_lower_idx ON users (LOWER(email));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_email_lower_idx;
+-- +goose StatementEnd